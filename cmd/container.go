@@ -0,0 +1,406 @@
+package main
+
+import (
+	"GOHW-1/internal/analytics"
+	"GOHW-1/internal/backup"
+	"GOHW-1/internal/clock"
+	"GOHW-1/internal/configuration"
+	"GOHW-1/internal/controller"
+	"GOHW-1/internal/db"
+	"GOHW-1/internal/grpcserver"
+	"GOHW-1/internal/importer"
+	"GOHW-1/internal/infrastucture/kafka"
+	"GOHW-1/internal/metrics"
+	"GOHW-1/internal/repository/postgresql"
+	"GOHW-1/internal/service"
+	"GOHW-1/internal/storage"
+	"GOHW-1/internal/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	statsFlushInterval  = time.Minute
+	configWatchInterval = 5 * time.Second
+	// metricsReadHeaderTimeout protects the metrics server of the interactive mode from slow clients
+	metricsReadHeaderTimeout = 5 * time.Second
+	// tracingShutdownTimeout limits flushing of trace spans on exit when the collector does not answer
+	tracingShutdownTimeout = 5 * time.Second
+)
+
+// container creates dependencies of commands on first use, so every command connects only to what it needs.
+// Errors name the dependency that cannot be created
+type container struct {
+	ctx     context.Context
+	wg      *sync.WaitGroup
+	config  *configuration.Config
+	runtime *configuration.Runtime
+	clock   clock.Clock
+	logger  *slog.Logger
+	// args are command line arguments the config was loaded from, they are loaded again on reload
+	args     []string
+	watching bool
+
+	storage               *storage.Storage
+	service               *service.Service
+	database              *db.Database
+	sender                controller.Sender
+	orderController       *controller.OrderController
+	pickUpPointController *controller.PickUpPointController
+
+	closers []func()
+	mutex   sync.Mutex
+}
+
+func newContainer(ctx context.Context, wg *sync.WaitGroup, config *configuration.Config, runtime *configuration.Runtime,
+	logger *slog.Logger, args []string) *container {
+	c := &container{ctx: ctx, wg: wg, config: config, runtime: runtime, clock: clock.System(), logger: logger, args: args}
+	if !config.Now.IsZero() {
+		c.clock = clock.NewFake(config.Now)
+	}
+	return c
+}
+
+// WatchConfig reloads runtime settings on SIGHUP or config file change while the command runs
+func (c *container) WatchConfig() {
+	if c.watching {
+		return
+	}
+	c.watching = true
+	watcher := configuration.NewWatcher(c.args, c.config, c.runtime)
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		watcher.Run(c.ctx, configWatchInterval)
+	}()
+}
+
+// StartTracing installs the exporter of trace spans (stdout spans go to stderr, next to logs),
+// spans that are not exported yet are flushed on Close
+func (c *container) StartTracing() error {
+	shutdown, err := tracing.Setup(c.ctx, &c.config.Tracing, os.Stderr)
+	if err != nil {
+		return err
+	}
+	c.onClose(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			c.logger.Warn("cannot flush trace spans", "error", err)
+		}
+	})
+	return nil
+}
+
+// Storage returns the JSON storage in the working directory
+func (c *container) Storage() (*storage.Storage, error) {
+	if c.storage != nil {
+		return c.storage, nil
+	}
+	strg, err := storage.New(c.clock, c.runtime.Policies, c.logger.With("component", "storage"))
+	if err != nil {
+		return nil, fmt.Errorf("cannot open JSON storage: %w", err)
+	}
+	c.storage = &strg
+	return c.storage, nil
+}
+
+// Service returns the service over the JSON storage
+func (c *container) Service() (*service.Service, error) {
+	if c.service != nil {
+		return c.service, nil
+	}
+	strg, err := c.Storage()
+	if err != nil {
+		return nil, err
+	}
+	svc := service.New(strg)
+	c.service = &svc
+	return c.service, nil
+}
+
+// Importer returns the importer of the JSON storage into PostgreSQL
+func (c *container) Importer() (*importer.Importer, error) {
+	strg, err := c.Storage()
+	if err != nil {
+		return nil, err
+	}
+	database, err := c.Database()
+	if err != nil {
+		return nil, err
+	}
+	return importer.New(strg, database, postgresql.NewOrders(*database), postgresql.NewPickUpPoints(*database)), nil
+}
+
+// Backend returns the storage of the given name for export and restore, see backup.Backends
+func (c *container) Backend(name string) (backup.Backend, error) {
+	switch name {
+	case "json":
+		strg, err := c.Storage()
+		if err != nil {
+			return nil, err
+		}
+		return backup.NewJSONBackend(strg), nil
+	case "postgres":
+		database, err := c.Database()
+		if err != nil {
+			return nil, err
+		}
+		return backup.NewPostgresBackend(database, postgresql.NewOrders(*database), postgresql.NewPickUpPoints(*database),
+			postgresql.NewRequestStats(*database)), nil
+	}
+	return nil, fmt.Errorf("unknown backend %q", name)
+}
+
+// Export writes all data of the backend to a new archive at path, the archive is removed if the export fails
+func (c *container) Export(backend backup.Backend, backendName string, path string) (*backup.Manifest, error) {
+	archive, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := backup.Export(c.ctx, backend, backendName, c.clock.Now(), archive)
+	if closeErr := archive.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Database returns the PostgreSQL connection pool
+func (c *container) Database() (*db.Database, error) {
+	if c.database != nil {
+		return c.database, nil
+	}
+	dbCredentials := &c.config.Postgres
+	database, err := db.NewDb(c.ctx, dbCredentials, c.logger.With("component", "db"))
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to PostgreSQL at %s:%s: %w", dbCredentials.Host, dbCredentials.Port, err)
+	}
+	c.database = database
+	c.onClose(func() { database.GetPool(c.ctx).Close() })
+	if err := metrics.RegisterPool(database.GetPool(c.ctx)); err != nil {
+		c.logger.Warn("connection pool metrics are not exposed", "error", err)
+	}
+	return c.database, nil
+}
+
+// Migrate runs a migration command on the database, see db.MigrateCommands
+func (c *container) Migrate(command string, out io.Writer) error {
+	database, err := c.Database()
+	if err != nil {
+		return err
+	}
+	if err := database.Migrate(c.ctx, command, out); err != nil {
+		return fmt.Errorf("migrate %s failed: %w", command, err)
+	}
+	return nil
+}
+
+// ServeMetrics serves /metrics on the address until the context is cancelled
+func (c *container) ServeMetrics(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("cannot serve metrics: %w", err)
+	}
+	serveMux := http.NewServeMux()
+	serveMux.Handle("/metrics", metrics.Handler())
+	server := &http.Server{Handler: serveMux, ReadHeaderTimeout: metricsReadHeaderTimeout}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			c.logger.Error("metrics server stopped", "error", err)
+		}
+	}()
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		<-c.ctx.Done()
+		server.Close()
+	}()
+	return nil
+}
+
+// ServeGRPC serves the gRPC API on the configured address until the context is cancelled, nothing is served
+// without an address. Calls use the repository and events sink of the http controller
+func (c *container) ServeGRPC() error {
+	if c.config.GRPC.Address == "" {
+		return nil
+	}
+	pickUpPointController, err := c.PickUpPointController()
+	if err != nil {
+		return err
+	}
+	orderController, err := c.OrderController()
+	if err != nil {
+		return err
+	}
+	var options []grpc.ServerOption
+	if !c.config.GRPC.Insecure {
+		tlsCredentials, err := credentials.NewServerTLSFromFile(c.config.HTTP.CertFile, c.config.HTTP.KeyFile)
+		if err != nil {
+			return fmt.Errorf("cannot load TLS certificate of the gRPC server: %w", err)
+		}
+		options = append(options, grpc.Creds(tlsCredentials))
+	}
+	listener, err := net.Listen("tcp", c.config.GRPC.Address)
+	if err != nil {
+		return fmt.Errorf("cannot serve gRPC: %w", err)
+	}
+
+	server := grpcserver.New(pickUpPointController.Repo, orderController, pickUpPointController.Sender, c.config.HTTP,
+		c.logger.With("component", "grpc")).Register(options...)
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		if err := server.Serve(listener); err != nil {
+			c.logger.Error("gRPC server stopped", "error", err)
+		}
+	}()
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		<-c.ctx.Done()
+		server.GracefulStop()
+	}()
+	return nil
+}
+
+// Sender returns the configured events sink
+func (c *container) Sender() (controller.Sender, error) {
+	if c.sender != nil {
+		return c.sender, nil
+	}
+	sender, err := controller.NewSender(&c.config.Events, &c.config.Kafka, c.logger.With("component", "kafka"))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create events sink: %w", err)
+	}
+	c.sender = sender
+	c.onClose(func() { sender.Close() })
+	return c.sender, nil
+}
+
+// OrderController returns the controller of order commands and the interactive mode
+func (c *container) OrderController() (*controller.OrderController, error) {
+	if c.orderController != nil {
+		return c.orderController, nil
+	}
+	svc, err := c.Service()
+	if err != nil {
+		return nil, err
+	}
+	c.orderController = controller.NewOrderController(svc, c.runtime, c.clock, c.logger, c.wg, c.ctx)
+	return c.orderController, nil
+}
+
+// PickUpPointController returns the http controller. When events go to kafka, it also starts the consumer
+// that aggregates request analytics for the admin endpoints
+func (c *container) PickUpPointController() (*controller.PickUpPointController, error) {
+	if c.pickUpPointController != nil {
+		return c.pickUpPointController, nil
+	}
+	database, err := c.Database()
+	if err != nil {
+		return nil, err
+	}
+	databaseHealth := db.NewHealth(database, c.config.Postgres.Pool.HealthCheckInterval, c.logger.With("component", "db"))
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		databaseHealth.Run(c.ctx)
+	}()
+	sender, err := c.Sender()
+	if err != nil {
+		return nil, err
+	}
+
+	health := &c.config.Health
+	dependencies := []controller.Dependency{
+		controller.DatabaseDependency(configuration.DependencyPostgres, databaseHealth, health.IsCritical(configuration.DependencyPostgres)),
+		{
+			Name:     configuration.DependencyStorage,
+			Critical: health.IsCritical(configuration.DependencyStorage),
+			Check:    func(context.Context) error { return storage.CheckFiles() },
+		},
+	}
+	if c.config.Events.Type == configuration.SenderKafka {
+		dependencies = append(dependencies, controller.ProducerDependency(configuration.DependencyKafkaProducer, sender,
+			health.IsCritical(configuration.DependencyKafkaProducer)))
+	}
+
+	adminController := controller.NewAdminController(nil)
+	if _, ok := sender.(*controller.KafkaSender); ok {
+		consumer := c.startAnalytics(adminController, database)
+		dependencies = append(dependencies, controller.ConsumerDependency(configuration.DependencyKafkaConsumer, consumer,
+			health.IsCritical(configuration.DependencyKafkaConsumer)))
+	}
+
+	c.pickUpPointController = controller.NewPickUpPointController(database, sender)
+	c.pickUpPointController.Admin = adminController
+	c.pickUpPointController.HTTP = c.config.HTTP
+	c.pickUpPointController.Runtime = c.runtime
+	c.pickUpPointController.Dependencies = dependencies
+	c.pickUpPointController.Logger = c.logger.With("component", "http")
+	return c.pickUpPointController, nil
+}
+
+// startAnalytics runs the kafka consumer (group) and the aggregator of request statistics in go-routines
+func (c *container) startAnalytics(adminController *controller.AdminController, database *db.Database) *controller.Consumer {
+	aggregator := analytics.NewAggregator(postgresql.NewRequestStats(*database))
+	adminController.Stats = aggregator
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		aggregator.Run(c.ctx, statsFlushInterval)
+	}()
+
+	consumer := controller.NewConsumer(&c.config.Kafka, func(message kafka.LoggingMessage) {
+		aggregator.Record(analytics.Event{
+			Method:   message.Method,
+			Route:    message.Route,
+			Status:   message.Status,
+			Duration: message.Duration,
+			Time:     message.Time,
+		})
+	}, c.logger.With("component", "kafka"))
+	adminController.Consumer = consumer
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		if err := consumer.Run(c.ctx); err != nil {
+			c.logger.Error("kafka consumer stopped", "error", err)
+		}
+	}()
+	return consumer
+}
+
+func (c *container) onClose(closer func()) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.closers = append(c.closers, closer)
+}
+
+// Close releases created dependencies in reverse order of creation
+func (c *container) Close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for i := len(c.closers) - 1; i >= 0; i-- {
+		c.closers[i]()
+	}
+	c.closers = nil
+}
@@ -1,18 +1,27 @@
 package main
 
 import (
+	"GOHW-1/internal/backup"
+	"GOHW-1/internal/cli"
 	"GOHW-1/internal/configuration"
 	"GOHW-1/internal/controller"
 	"GOHW-1/internal/db"
-	"GOHW-1/internal/infrastucture/kafka"
-	"GOHW-1/internal/service"
-	"GOHW-1/internal/storage"
+	"GOHW-1/internal/logger"
+	"GOHW-1/internal/output"
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"syscall"
+	"time"
 )
 
 func main() {
@@ -23,115 +32,244 @@ func main() {
 	defer cancel()
 	var wg sync.WaitGroup
 
+	config, args, err := configuration.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Printf("Usage: %s [global flags] <command> [flags]\n\nGLOBAL FLAGS\n", filepath.Base(os.Args[0]))
+		configuration.PrintFlags(os.Stdout)
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	runtime := configuration.NewRuntime(config.Runtime)
+	appLogger := logger.New(os.Stderr, config.Log.Format, runtime.LogLevel())
+	slog.SetDefault(appLogger)
+	deps := newContainer(ctx, &wg, config, runtime, appLogger, os.Args[1:])
+	if err := deps.StartTracing(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
 	go func() {
 		<-signals
-		log.Println("\nReceived shutdown signal, exiting...")
+		appLogger.Info("received shutdown signal, exiting")
 
 		cancel()
 		wg.Wait()
+		deps.Close()
 		os.Exit(0)
 	}()
 
-	// Storage initialization
-	strg, err := storage.New()
-	if err != nil {
-		log.Fatalf("cannot connect to storage: %v", err)
-	}
+	var commands *cli.Registry
+	commands = cli.NewRegistry(filepath.Base(os.Args[0]),
+		cli.Command{
+			Name:    "http",
+			Summary: "Launches http server",
+			Description: "With -migrate, pending migrations are applied first (instances started together wait for each other).\n" +
+				"The gRPC API is served alongside when GRPC_ADDRESS is set",
+			Example: "http -migrate",
+			Flags:   func(config *configuration.AppConfig) *flag.FlagSet { return &config.HTTP.FlagSet },
+			Run: func(config *configuration.AppConfig, _ []string) error {
+				if *config.HTTP.Migrate {
+					if err := deps.Migrate("up", os.Stdout); err != nil {
+						return err
+					}
+				}
+				pickUpPointController, err := deps.PickUpPointController()
+				if err != nil {
+					return err
+				}
+				if err := deps.ServeGRPC(); err != nil {
+					return err
+				}
+				deps.WatchConfig()
+				pickUpPointController.StartHTTPServer()
+				return nil
+			},
+		},
+	)
+	commands.Register(controller.OrderCommands(deps.OrderController)...)
+	commands.Register(
+		cli.Command{
+			Name:    "interactive",
+			Summary: "Launches an interactive mode that accepts every order command plus write and read of pick-up points",
+			Example: "interactive -metrics-addr=:9090",
+			Flags:   func(config *configuration.AppConfig) *flag.FlagSet { return &config.Interactive.FlagSet },
+			Run: func(config *configuration.AppConfig, _ []string) error {
+				orderController, err := deps.OrderController()
+				if err != nil {
+					return err
+				}
+				if *config.Interactive.MetricsAddress != "" {
+					if err := deps.ServeMetrics(*config.Interactive.MetricsAddress); err != nil {
+						return err
+					}
+				}
+				deps.WatchConfig()
+				orderController.InteractiveCommand()
+				return nil
+			},
+		},
+		cli.Command{
+			Name:     "help",
+			Summary:  "Shows commands or help of the given command",
+			Args:     "[command]",
+			Complete: func() []string { return commands.Names() },
+			Run: func(_ *configuration.AppConfig, args []string) error {
+				if len(args) == 0 {
+					return commands.PrintHelp(os.Stdout, "")
+				}
+				return commands.PrintHelp(os.Stdout, args[0])
+			},
+		},
+		cli.Command{
+			Name:    "import",
+			Summary: "Imports orders and pick-up points from the JSON files into PostgreSQL",
+			Description: "Records are upserted by ID, so the import can be run again. Pick-up points keep their IDs.\n" +
+				"The report compares counts of the files and the database and lists mismatches, the command fails if any",
+			Example: "import -dry-run",
+			Flags:   func(config *configuration.AppConfig) *flag.FlagSet { return &config.Import.FlagSet },
+			Run: func(config *configuration.AppConfig, _ []string) error {
+				if err := output.ValidateFormat(*config.Import.Output); err != nil {
+					return &cli.UsageError{Command: "import", Err: err}
+				}
+				jsonImporter, err := deps.Importer()
+				if err != nil {
+					return err
+				}
+				report, err := jsonImporter.Run(ctx, *config.Import.DryRun)
+				if err != nil {
+					return fmt.Errorf("import failed: %w", err)
+				}
+				if err := report.Print(os.Stdout, *config.Import.Output); err != nil {
+					return err
+				}
+				if len(report.Mismatches) > 0 {
+					return fmt.Errorf("import finished with %d mismatches", len(report.Mismatches))
+				}
+				return nil
+			},
+		},
+		cli.Command{
+			Name:    "export",
+			Summary: "Exports all pick-up points, orders, refunds and request statistics into an archive",
+			Description: "The archive is a gzipped tar of JSON lines per entity and a manifest with record counts\n" +
+				"and SHA-256 checksums. The JSON storage has no request statistics",
+			Args:    "<archive>",
+			Example: "export -backend=postgres backup.tar.gz",
+			Flags:   func(config *configuration.AppConfig) *flag.FlagSet { return &config.Export.FlagSet },
+			Run: func(config *configuration.AppConfig, args []string) error {
+				if err := validateBackup("export", *config.Export.Backend, args); err != nil {
+					return err
+				}
+				backend, err := deps.Backend(*config.Export.Backend)
+				if err != nil {
+					return err
+				}
+				manifest, err := deps.Export(backend, *config.Export.Backend, args[0])
+				if err != nil {
+					return fmt.Errorf("export failed: %w", err)
+				}
+				printManifest(os.Stdout, "Exported", manifest)
+				return nil
+			},
+		},
+		cli.Command{
+			Name:    "restore",
+			Summary: "Replaces all data of a backend with an archive of the export command",
+			Description: "The manifest, checksums and every record are validated before anything is written,\n" +
+				"then all pick-up points, orders, refunds and request statistics of the backend are replaced",
+			Args:    "<archive>",
+			Example: "restore -backend=postgres backup.tar.gz",
+			Flags:   func(config *configuration.AppConfig) *flag.FlagSet { return &config.Restore.FlagSet },
+			Run: func(config *configuration.AppConfig, args []string) error {
+				if err := validateBackup("restore", *config.Restore.Backend, args); err != nil {
+					return err
+				}
+				backend, err := deps.Backend(*config.Restore.Backend)
+				if err != nil {
+					return err
+				}
+				archive, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer archive.Close()
+				manifest, err := backup.Restore(ctx, backend, archive)
+				if err != nil {
+					return fmt.Errorf("restore failed: %w", err)
+				}
+				printManifest(os.Stdout, "Restored", manifest)
+				return nil
+			},
+		},
+		cli.Command{
+			Name:        "migrate",
+			Summary:     "Applies or rolls back database migrations embedded in the binary",
+			Description: "up applies all pending migrations, down rolls back the last one, redo rolls back and applies it again,\nstatus lists migrations",
+			Args:        strings.Join(db.MigrateCommands, "|"),
+			Example:     "migrate up",
+			Complete:    func() []string { return db.MigrateCommands },
+			Run: func(_ *configuration.AppConfig, args []string) error {
+				if len(args) != 1 || !slices.Contains(db.MigrateCommands, args[0]) {
+					return &cli.UsageError{Command: "migrate", Err: errors.New("expected up, down, status or redo")}
+				}
+				return deps.Migrate(args[0], os.Stdout)
+			},
+		},
+		cli.Command{
+			Name:        "config",
+			Summary:     "Shows the configuration with secrets redacted",
+			Description: "Settings are read from defaults, the YAML file (-config or $CONFIG_FILE), environment variables\nand global flags, each overriding the previous one. Run `-h` before a command to list global flags",
+			Args:        "show",
+			Complete:    func() []string { return []string{"show"} },
+			Run: func(_ *configuration.AppConfig, args []string) error {
+				if len(args) != 1 || args[0] != "show" {
+					return &cli.UsageError{Command: "config", Err: errors.New("expected `config show`")}
+				}
+				return config.Show(os.Stdout)
+			},
+		},
+		cli.Command{
+			Name:        "completion",
+			Summary:     "Prints a shell completion script",
+			Description: "Add `source <(" + filepath.Base(os.Args[0]) + " completion bash)` to ~/.bashrc (or zsh to ~/.zshrc)",
+			Args:        strings.Join(cli.Shells, "|"),
+			Complete:    func() []string { return cli.Shells },
+			Run: func(_ *configuration.AppConfig, args []string) error {
+				if len(args) != 1 {
+					return &cli.UsageError{Command: "completion", Err: errors.New("expected a shell name")}
+				}
+				return commands.WriteCompletion(os.Stdout, args[0])
+			},
+		},
+	)
 
-	// Service initialization
-	svc := service.New(&strg)
-
-	// Database initialization
-	dbCredentials := configuration.NewDBCredentials()
-	dbCredentials.SetEnv()
-	database, err := db.NewDb(ctx, dbCredentials)
-	if err != nil {
-		log.Fatalf("cannot connect to database: %v", err)
+	code := commands.Execute(args, os.Stdout, os.Stderr)
+	cancel()
+	wg.Wait()
+	deps.Close()
+	if code != 0 {
+		os.Exit(code)
 	}
-	defer database.GetPool(ctx).Close()
+}
 
-	// Kafka producer initialization
-	topicName := configuration.GetTopicName()
-	brokers := configuration.GetBrokers()
-	kafkaProducer, err := kafka.NewProducer(*brokers)
-	if err != nil {
-		log.Fatalf("cannot connect to kafka: %v", err)
+// validateBackup checks the backend and the archive argument of export and restore
+func validateBackup(command string, backend string, args []string) error {
+	if !slices.Contains(backup.Backends, backend) {
+		return &cli.UsageError{Command: command, Err: fmt.Errorf("unknown backend %q: expected json or postgres", backend)}
 	}
-	defer kafkaProducer.Close()
-
-	// Kafka consumer (group) initialization in a go-routine
-	go controller.ConsumerGroup(*brokers, *topicName)
-
-	// Order controller initialization
-	orderController := controller.NewOrderController(&svc, &wg, ctx)
-
-	// Pick-up point controller initialization
-	sender := controller.NewKafkaSender(kafkaProducer, *topicName)
-	pickUpPointController := controller.NewPickUpPointController(database, sender)
-
-	config := configuration.DefaultConfig()
-
-	if len(os.Args) < 2 {
-		log.Fatal("you need to write a command")
+	if len(args) != 1 {
+		return &cli.UsageError{Command: command, Err: errors.New("expected an archive path")}
 	}
+	return nil
+}
 
-	switch os.Args[1] {
-	case "http":
-		pickUpPointController.StartHTTPServer()
-	case "cr-take":
-		crTake := config.CrTake.FlagSet
-		if err := crTake.Parse(os.Args[2:]); err != nil {
-			log.Fatalf("failed to parse command line flags for cr-take: %v", err)
-		}
-		if err := orderController.CourierTakeCommand(*config.CrTake.OrderID, *config.CrTake.ClientID, *config.CrTake.AvailableTime,
-			*config.CrTake.Weight, *config.CrTake.Price, *config.CrTake.Packaging); err != nil {
-			log.Fatal(err)
-		}
-	case "cr-return":
-		crReturn := config.CrReturn.FlagSet
-		if err := crReturn.Parse(os.Args[2:]); err != nil {
-			log.Fatalf("failed to parse command line flags for cr-return: %v", err)
-		}
-		if err := orderController.CourierReturnCommand(*config.CrReturn.OrderID); err != nil {
-			log.Fatal(err)
-		}
-	case "cl-give":
-		clGive := config.ClGive.FlagSet
-		if err := clGive.Parse(os.Args[2:]); err != nil {
-			log.Fatalf("failed to parse command line flags for cl-give: %v", err)
-		}
-		if err := orderController.ClientGiveCommand(*config.ClGive.ClientID, *config.ClGive.OrdersID); err != nil {
-			log.Fatal(err)
-		}
-	case "cl-orders":
-		clOrders := config.ClOrders.FlagSet
-		if err := clOrders.Parse(os.Args[2:]); err != nil {
-			log.Fatalf("failed to parse command line flags for cl-orders: %v", err)
-		}
-		if err := orderController.ClientOrdersCommand(*config.ClOrders.ClientID, *config.ClOrders.N, *config.ClOrders.OnlyUserOrders); err != nil {
-			log.Fatal(err)
-		}
-	case "cl-refund":
-		clRefund := config.ClRefund.FlagSet
-		if err := clRefund.Parse(os.Args[2:]); err != nil {
-			log.Fatalf("failed to parse command line flags for cl-refund: %v", err)
-		}
-		if err := orderController.ClientRefundCommand(*config.ClRefund.ClientID, *config.ClRefund.OrderID); err != nil {
-			log.Fatal(err)
-		}
-	case "refund-list":
-		refundList := config.RefundList.FlagSet
-		if err := refundList.Parse(os.Args[2:]); err != nil {
-			log.Fatalf("failed to parse command line flags for refund-list: %v", err)
-		}
-		if err := orderController.RefundListCommand(*config.RefundList.PageNumber); err != nil {
-			log.Fatal(err)
-		}
-	case "interactive":
-		orderController.InteractiveCommand()
-	case "help":
-		orderController.HelpCommand()
-	default:
-		log.Fatal("unknown command. Try to use `help` command")
+func printManifest(w io.Writer, action string, manifest *backup.Manifest) {
+	fmt.Fprintf(w, "%s %s backup of %s (format version %d):\n", action, manifest.Backend,
+		manifest.CreatedAt.Format(time.RFC3339), manifest.Version)
+	for _, entity := range manifest.Entities {
+		fmt.Fprintf(w, "  %-15s %d records\n", entity.Name, entity.Records)
 	}
 }
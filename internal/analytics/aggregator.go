@@ -0,0 +1,163 @@
+package analytics
+
+import (
+	"GOHW-1/internal/model"
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxEventsPerRoute bounds memory used by a single route
+const maxEventsPerRoute = 100000
+
+// Windows are the sliding windows that are persisted
+var Windows = []time.Duration{time.Minute, 5 * time.Minute, time.Hour}
+
+type Event struct {
+	Method   string
+	Route    string
+	Status   int
+	Duration time.Duration
+	Time     time.Time
+}
+
+type statsRepo interface {
+	Save(ctx context.Context, stats []model.RouteStats) error
+	List(ctx context.Context, route string, limit int) ([]model.RouteStats, error)
+}
+
+// Aggregator computes per-route request statistics over sliding windows
+type Aggregator struct {
+	repo      statsRepo
+	maxWindow time.Duration
+	events    map[string][]Event
+	now       func() time.Time
+	mutex     sync.Mutex
+}
+
+func NewAggregator(repo statsRepo) *Aggregator {
+	return &Aggregator{
+		repo:      repo,
+		maxWindow: Windows[len(Windows)-1],
+		events:    make(map[string][]Event),
+		now:       time.Now,
+	}
+}
+
+// Record adds a request event. Events of a route are kept in time order: they arrive out of order from
+// different partitions and replays of buffered events, and prune drops the oldest ones
+func (a *Aggregator) Record(event Event) {
+	key := routeKey(event)
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	events := a.events[key]
+	i := sort.Search(len(events), func(i int) bool { return events[i].Time.After(event.Time) })
+	events = append(events, Event{})
+	copy(events[i+1:], events[i:])
+	events[i] = event
+	if len(events) > maxEventsPerRoute {
+		events = events[len(events)-maxEventsPerRoute:]
+	}
+	a.events[key] = events
+}
+
+// Stats returns statistics of every route over the last window
+func (a *Aggregator) Stats(window time.Duration) []model.RouteStats {
+	now := a.now()
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.prune(now)
+
+	stats := make([]model.RouteStats, 0, len(a.events))
+	for route, events := range a.events {
+		durations := make([]time.Duration, 0, len(events))
+		routeStats := model.RouteStats{Route: route, WindowSeconds: int64(window.Seconds()), ComputedAt: now}
+		for _, event := range events {
+			if now.Sub(event.Time) > window {
+				continue
+			}
+			routeStats.Count++
+			if event.Status >= http.StatusInternalServerError {
+				routeStats.Errors++
+			}
+			durations = append(durations, event.Duration)
+		}
+		if routeStats.Count == 0 {
+			continue
+		}
+
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		routeStats.ErrorRate = float64(routeStats.Errors) / float64(routeStats.Count)
+		routeStats.P50 = percentile(durations, 50)
+		routeStats.P95 = percentile(durations, 95)
+		routeStats.P99 = percentile(durations, 99)
+		stats = append(stats, routeStats)
+	}
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Route < stats[j].Route })
+	return stats
+}
+
+// History returns persisted statistics of a route (all routes if empty), newest first
+func (a *Aggregator) History(ctx context.Context, route string, limit int) ([]model.RouteStats, error) {
+	return a.repo.List(ctx, route, limit)
+}
+
+// Run persists statistics of every window each interval until the context is cancelled
+func (a *Aggregator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.Flush(ctx); err != nil {
+				slog.ErrorContext(ctx, "cannot persist request stats", "error", err)
+			}
+		}
+	}
+}
+
+// Flush persists statistics of every window
+func (a *Aggregator) Flush(ctx context.Context) error {
+	stats := make([]model.RouteStats, 0)
+	for _, window := range Windows {
+		stats = append(stats, a.Stats(window)...)
+	}
+	if len(stats) == 0 {
+		return nil
+	}
+	return a.repo.Save(ctx, stats)
+}
+
+// prune removes events older than the largest window, events are sorted by time
+func (a *Aggregator) prune(now time.Time) {
+	for route, events := range a.events {
+		first := sort.Search(len(events), func(i int) bool { return now.Sub(events[i].Time) <= a.maxWindow })
+		if first == len(events) {
+			delete(a.events, route)
+			continue
+		}
+		a.events[route] = events[first:]
+	}
+}
+
+func routeKey(event Event) string {
+	return event.Method + " " + event.Route
+}
+
+// percentile uses the nearest-rank method on sorted durations and returns milliseconds
+func percentile(sorted []time.Duration, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return float64(sorted[rank-1]) / float64(time.Millisecond)
+}
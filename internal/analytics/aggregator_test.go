@@ -0,0 +1,108 @@
+package analytics
+
+import (
+	"GOHW-1/internal/model"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type memoryStatsRepo struct {
+	saved []model.RouteStats
+}
+
+func (r *memoryStatsRepo) Save(_ context.Context, stats []model.RouteStats) error {
+	r.saved = append(r.saved, stats...)
+	return nil
+}
+
+func (r *memoryStatsRepo) List(_ context.Context, _ string, _ int) ([]model.RouteStats, error) {
+	return r.saved, nil
+}
+
+func TestAggregator_Stats(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	t.Run("smoke test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		aggregator := NewAggregator(&memoryStatsRepo{})
+		aggregator.now = func() time.Time { return now }
+		for i := 1; i <= 100; i++ {
+			status := http.StatusOK
+			if i%10 == 0 {
+				status = http.StatusInternalServerError
+			}
+			aggregator.Record(Event{Method: http.MethodGet, Route: "/pick-up-point", Status: status,
+				Duration: time.Duration(i) * time.Millisecond, Time: now.Add(-time.Second)})
+		}
+
+		// act
+		stats := aggregator.Stats(time.Minute)
+
+		// assert
+		require.Len(t, stats, 1)
+		assert.Equal(t, "GET /pick-up-point", stats[0].Route)
+		assert.Equal(t, int64(100), stats[0].Count)
+		assert.Equal(t, int64(10), stats[0].Errors)
+		assert.Equal(t, 0.1, stats[0].ErrorRate)
+		assert.Equal(t, 50.0, stats[0].P50)
+		assert.Equal(t, 95.0, stats[0].P95)
+		assert.Equal(t, 99.0, stats[0].P99)
+	})
+	t.Run("sliding window test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		aggregator := NewAggregator(&memoryStatsRepo{})
+		aggregator.now = func() time.Time { return now }
+		aggregator.Record(Event{Method: http.MethodGet, Route: "/pick-up-point", Status: http.StatusOK, Time: now.Add(-2 * time.Hour)})
+		aggregator.Record(Event{Method: http.MethodGet, Route: "/pick-up-point", Status: http.StatusOK, Time: now.Add(-10 * time.Minute)})
+		aggregator.Record(Event{Method: http.MethodPost, Route: "/pick-up-point", Status: http.StatusOK, Time: now.Add(-10 * time.Second)})
+
+		// act
+		lastMinute := aggregator.Stats(time.Minute)
+		lastHour := aggregator.Stats(time.Hour)
+
+		// assert
+		require.Len(t, lastMinute, 1)
+		assert.Equal(t, "POST /pick-up-point", lastMinute[0].Route)
+		require.Len(t, lastHour, 2)
+		assert.Equal(t, int64(1), lastHour[0].Count)
+		assert.Equal(t, int64(1), lastHour[1].Count)
+	})
+	t.Run("out of order test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		aggregator := NewAggregator(&memoryStatsRepo{})
+		aggregator.now = func() time.Time { return now }
+		aggregator.Record(Event{Method: http.MethodGet, Route: "/pick-up-point", Status: http.StatusOK, Time: now.Add(-10 * time.Second)})
+		aggregator.Record(Event{Method: http.MethodGet, Route: "/pick-up-point", Status: http.StatusOK, Time: now.Add(-2 * time.Hour)})
+		aggregator.Record(Event{Method: http.MethodGet, Route: "/pick-up-point", Status: http.StatusOK, Time: now.Add(-20 * time.Second)})
+
+		// act
+		stats := aggregator.Stats(time.Hour)
+
+		// assert
+		require.Len(t, stats, 1)
+		assert.Equal(t, int64(2), stats[0].Count)
+		assert.Len(t, aggregator.events["GET /pick-up-point"], 2)
+	})
+	t.Run("flush test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		repo := &memoryStatsRepo{}
+		aggregator := NewAggregator(repo)
+		aggregator.now = func() time.Time { return now }
+		aggregator.Record(Event{Method: http.MethodGet, Route: "/pick-up-point", Status: http.StatusOK, Time: now})
+
+		// act
+		err := aggregator.Flush(context.Background())
+
+		// assert
+		require.NoError(t, err)
+		assert.Len(t, repo.saved, len(Windows))
+	})
+}
@@ -0,0 +1,268 @@
+package backup
+
+import (
+	"GOHW-1/internal/model"
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+const (
+	// Format names archives of this tool in the manifest
+	Format = "gohw-backup"
+	// Version is the version of the archive layout written by Write, Read accepts only this version
+	Version      = 1
+	manifestFile = "manifest.json"
+)
+
+// Snapshot is the state of all domain data. Orders are available and given orders, refunds are refunded orders,
+// request statistics are the audit data of the http API
+type Snapshot struct {
+	PickUpPoints []model.PickUpPoint
+	Orders       []model.StoredOrder
+	Refunds      []model.StoredOrder
+	RequestStats []model.RouteStats
+}
+
+// Manifest describes the archive, it is the first file of the archive
+type Manifest struct {
+	Format    string       `json:"format"`
+	Version   int          `json:"version"`
+	CreatedAt time.Time    `json:"created_at"`
+	Backend   string       `json:"backend"`
+	Entities  []EntityFile `json:"entities"`
+}
+
+// EntityFile describes the JSON lines file of one entity
+type EntityFile struct {
+	Name    string `json:"name"`
+	File    string `json:"file"`
+	Records int    `json:"records"`
+	SHA256  string `json:"sha256"`
+}
+
+// entity encodes records of a snapshot to JSON lines and decodes them back
+type entity struct {
+	name   string
+	encode func(snapshot *Snapshot) ([]byte, int, error)
+	decode func(data []byte, snapshot *Snapshot) (int, error)
+}
+
+var entities = []entity{
+	newEntity("pick_up_points", func(snapshot *Snapshot) *[]model.PickUpPoint { return &snapshot.PickUpPoints }),
+	newEntity("orders", func(snapshot *Snapshot) *[]model.StoredOrder { return &snapshot.Orders }),
+	newEntity("refunds", func(snapshot *Snapshot) *[]model.StoredOrder { return &snapshot.Refunds }),
+	newEntity("request_stats", func(snapshot *Snapshot) *[]model.RouteStats { return &snapshot.RequestStats }),
+}
+
+func newEntity[T any](name string, records func(snapshot *Snapshot) *[]T) entity {
+	return entity{
+		name: name,
+		encode: func(snapshot *Snapshot) ([]byte, int, error) {
+			var buffer bytes.Buffer
+			encoder := json.NewEncoder(&buffer)
+			for _, record := range *records(snapshot) {
+				if err := encoder.Encode(record); err != nil {
+					return nil, 0, err
+				}
+			}
+			return buffer.Bytes(), len(*records(snapshot)), nil
+		},
+		decode: func(data []byte, snapshot *Snapshot) (int, error) {
+			var decoded []T
+			scanner := bufio.NewScanner(bytes.NewReader(data))
+			scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+			for line := 1; scanner.Scan(); line++ {
+				var record T
+				decoder := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+				decoder.DisallowUnknownFields()
+				if err := decoder.Decode(&record); err != nil {
+					return 0, fmt.Errorf("line %d: %w", line, err)
+				}
+				decoded = append(decoded, record)
+			}
+			if err := scanner.Err(); err != nil {
+				return 0, err
+			}
+			*records(snapshot) = decoded
+			return len(decoded), nil
+		},
+	}
+}
+
+// Write writes the snapshot as a gzipped tar archive: the manifest and a JSON lines file per entity
+func Write(w io.Writer, backend string, createdAt time.Time, snapshot *Snapshot) (*Manifest, error) {
+	manifest := &Manifest{Format: Format, Version: Version, CreatedAt: createdAt.UTC(), Backend: backend}
+	files := make([][]byte, 0, len(entities))
+	for _, entity := range entities {
+		data, records, err := entity.encode(snapshot)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode %s: %w", entity.name, err)
+		}
+		checksum := sha256.Sum256(data)
+		manifest.Entities = append(manifest.Entities, EntityFile{
+			Name:    entity.name,
+			File:    entity.name + ".jsonl",
+			Records: records,
+			SHA256:  hex.EncodeToString(checksum[:]),
+		})
+		files = append(files, data)
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+	if err := writeFile(tarWriter, manifestFile, manifestData, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	for i, entityFile := range manifest.Entities {
+		if err := writeFile(tarWriter, entityFile.File, files[i], manifest.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func writeFile(tarWriter *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: modTime, Typeflag: tar.TypeReg}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return fmt.Errorf("cannot write %s: %w", name, err)
+	}
+	if _, err := tarWriter.Write(data); err != nil {
+		return fmt.Errorf("cannot write %s: %w", name, err)
+	}
+	return nil
+}
+
+// Read reads an archive written by Write. The manifest, checksums and record counts of all entities
+// are checked and records are validated before the snapshot is returned
+func Read(r io.Reader) (*Manifest, *Snapshot, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("not a backup archive: %w", err)
+	}
+	defer gzipReader.Close()
+
+	files := make(map[string][]byte)
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("not a backup archive: %w", err)
+		}
+		if _, ok := files[header.Name]; ok {
+			return nil, nil, fmt.Errorf("file %s is duplicated in the archive", header.Name)
+		}
+		if files[header.Name], err = io.ReadAll(tarReader); err != nil {
+			return nil, nil, fmt.Errorf("cannot read %s: %w", header.Name, err)
+		}
+	}
+
+	manifestData, ok := files[manifestFile]
+	if !ok {
+		return nil, nil, fmt.Errorf("the archive has no %s", manifestFile)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.Format != Format {
+		return nil, nil, fmt.Errorf("invalid manifest: format %q is not %q", manifest.Format, Format)
+	}
+	if manifest.Version != Version {
+		return nil, nil, fmt.Errorf("invalid manifest: version %d is not supported, expected %d", manifest.Version, Version)
+	}
+
+	entityFiles := make(map[string]EntityFile, len(manifest.Entities))
+	for _, entityFile := range manifest.Entities {
+		if _, ok := entityFiles[entityFile.Name]; ok {
+			return nil, nil, fmt.Errorf("invalid manifest: entity %s is listed twice", entityFile.Name)
+		}
+		entityFiles[entityFile.Name] = entityFile
+	}
+	snapshot := &Snapshot{}
+	for _, entity := range entities {
+		entityFile, ok := entityFiles[entity.name]
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid manifest: entity %s is missing", entity.name)
+		}
+		delete(entityFiles, entity.name)
+		data, ok := files[entityFile.File]
+		if !ok {
+			return nil, nil, fmt.Errorf("%s: file %s is missing in the archive", entity.name, entityFile.File)
+		}
+		if checksum := sha256.Sum256(data); hex.EncodeToString(checksum[:]) != entityFile.SHA256 {
+			return nil, nil, fmt.Errorf("%s: checksum of %s does not match the manifest", entity.name, entityFile.File)
+		}
+		records, err := entity.decode(data, snapshot)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: invalid record: %w", entity.name, err)
+		}
+		if records != entityFile.Records {
+			return nil, nil, fmt.Errorf("%s: %d records, the manifest lists %d", entity.name, records, entityFile.Records)
+		}
+	}
+	for name := range entityFiles {
+		return nil, nil, fmt.Errorf("invalid manifest: unknown entity %s", name)
+	}
+	if err := snapshot.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return &manifest, snapshot, nil
+}
+
+// Validate checks that IDs are unique and orders are in the states of their entity
+func (snapshot *Snapshot) Validate() error {
+	pickUpPointIDs := make(map[int64]bool, len(snapshot.PickUpPoints))
+	for _, pickUpPoint := range snapshot.PickUpPoints {
+		if pickUpPoint.ID <= 0 {
+			return fmt.Errorf("pick_up_points: ID %d must be positive", pickUpPoint.ID)
+		}
+		if pickUpPointIDs[pickUpPoint.ID] {
+			return fmt.Errorf("pick_up_points: ID %d is duplicated", pickUpPoint.ID)
+		}
+		pickUpPointIDs[pickUpPoint.ID] = true
+	}
+
+	for _, orders := range []struct {
+		name   string
+		orders []model.StoredOrder
+		states []string
+	}{
+		{"orders", snapshot.Orders, []string{model.OrderAvailable, model.OrderGiven}},
+		{"refunds", snapshot.Refunds, []string{model.OrderRefunded}},
+	} {
+		orderIDs := make(map[int]bool, len(orders.orders))
+		for _, order := range orders.orders {
+			if orderIDs[order.ID] {
+				return fmt.Errorf("%s: order %d is duplicated", orders.name, order.ID)
+			}
+			orderIDs[order.ID] = true
+			if !slices.Contains(orders.states, order.State) {
+				return fmt.Errorf("%s: order %d has state %q", orders.name, order.ID, order.State)
+			}
+		}
+	}
+	return nil
+}
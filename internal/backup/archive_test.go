@@ -0,0 +1,231 @@
+package backup
+
+import (
+	"GOHW-1/internal/model"
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+	"time"
+)
+
+var createdAt = time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+
+func testSnapshot() *Snapshot {
+	givenTime := time.Date(2024, 4, 2, 12, 0, 0, 0, time.UTC)
+	expirationDate := time.Date(2024, 4, 10, 12, 0, 0, 0, time.UTC)
+	return &Snapshot{
+		PickUpPoints: []model.PickUpPoint{{ID: 3, Name: "Central", Address: "Main street, 1", Contact: "+7 999 000-00-01"}},
+		Orders: []model.StoredOrder{
+			{Order: model.Order{ID: 1, ClientID: 10, ExpirationDate: expirationDate, Weight: 1, Price: 100, Packaging: "film"},
+				State: model.OrderAvailable},
+			{Order: model.Order{ID: 2, ClientID: 10, ExpirationDate: expirationDate, Weight: 2, Price: 200, Packaging: "box",
+				PickUpPointID: 3}, State: model.OrderGiven, GivenTime: &givenTime},
+		},
+		Refunds: []model.StoredOrder{
+			{Order: model.Order{ID: 4, ClientID: 20, ExpirationDate: expirationDate}, State: model.OrderRefunded, GivenTime: &givenTime},
+		},
+		RequestStats: []model.RouteStats{{Route: "GET /pick-up-point", WindowSeconds: 60, Count: 10, P50: 1.5, ComputedAt: givenTime}},
+	}
+}
+
+// rewrite returns the archive with files changed by change, the manifest is passed decoded
+func rewrite(t *testing.T, archive []byte, change func(manifest *Manifest, files map[string][]byte)) []byte {
+	t.Helper()
+	gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+	require.NoError(t, err)
+	tarReader := tar.NewReader(gzipReader)
+	var names []string
+	files := make(map[string][]byte)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, header.Name)
+		files[header.Name], err = io.ReadAll(tarReader)
+		require.NoError(t, err)
+	}
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(files[manifestFile], &manifest))
+	change(&manifest, files)
+	files[manifestFile], err = json.Marshal(manifest)
+	require.NoError(t, err)
+
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, name := range names {
+		require.NoError(t, writeFile(tarWriter, name, files[name], createdAt))
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+	return buffer.Bytes()
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+	t.Run("round trip test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var archive bytes.Buffer
+		snapshot := testSnapshot()
+
+		// act
+		written, err := Write(&archive, "postgres", createdAt, snapshot)
+		require.NoError(t, err)
+		manifest, read, err := Read(&archive)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, written, manifest)
+		assert.Equal(t, "postgres", manifest.Backend)
+		assert.Equal(t, Version, manifest.Version)
+		require.Len(t, manifest.Entities, 4)
+		assert.Equal(t, EntityFile{Name: "orders", File: "orders.jsonl", Records: 2, SHA256: written.Entities[1].SHA256}, manifest.Entities[1])
+		assert.Equal(t, snapshot, read)
+	})
+	t.Run("empty snapshot test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var archive bytes.Buffer
+
+		// act
+		_, err := Write(&archive, "json", createdAt, &Snapshot{})
+		require.NoError(t, err)
+		manifest, read, err := Read(&archive)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &Snapshot{}, read)
+		for _, entity := range manifest.Entities {
+			assert.Zero(t, entity.Records)
+		}
+	})
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+	var archive bytes.Buffer
+	_, err := Write(&archive, "json", createdAt, testSnapshot())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		change func(manifest *Manifest, files map[string][]byte)
+		err    string
+	}{
+		{
+			name:   "unsupported version test",
+			change: func(manifest *Manifest, _ map[string][]byte) { manifest.Version = 2 },
+			err:    "invalid manifest: version 2 is not supported, expected 1",
+		},
+		{
+			name:   "other format test",
+			change: func(manifest *Manifest, _ map[string][]byte) { manifest.Format = "tar" },
+			err:    `invalid manifest: format "tar" is not "gohw-backup"`,
+		},
+		{
+			name: "changed file test",
+			change: func(_ *Manifest, files map[string][]byte) {
+				files["orders.jsonl"] = bytes.Replace(files["orders.jsonl"], []byte(`"Price":100`), []byte(`"Price":1`), 1)
+			},
+			err: "orders: checksum of orders.jsonl does not match the manifest",
+		},
+		{
+			name:   "record count test",
+			change: func(manifest *Manifest, _ map[string][]byte) { manifest.Entities[2].Records = 5 },
+			err:    "refunds: 1 records, the manifest lists 5",
+		},
+		{
+			name:   "missing entity test",
+			change: func(manifest *Manifest, _ map[string][]byte) { manifest.Entities = manifest.Entities[:3] },
+			err:    "invalid manifest: entity request_stats is missing",
+		},
+		{
+			name: "order state test",
+			change: func(manifest *Manifest, files map[string][]byte) {
+				files["refunds.jsonl"] = bytes.Replace(files["refunds.jsonl"], []byte(`"refunded"`), []byte(`"given"`), 1)
+				manifest.Entities[2].SHA256 = checksum(files["refunds.jsonl"])
+			},
+			err: `refunds: order 4 has state "given"`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// arrange
+			changed := rewrite(t, archive.Bytes(), tt.change)
+
+			// act
+			_, _, err := Read(bytes.NewReader(changed))
+
+			// assert
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+type memoryBackend struct {
+	snapshot *Snapshot
+}
+
+func (backend *memoryBackend) Read(_ context.Context) (*Snapshot, error) {
+	return backend.snapshot, nil
+}
+
+func (backend *memoryBackend) Write(_ context.Context, snapshot *Snapshot) error {
+	backend.snapshot = snapshot
+	return nil
+}
+
+func TestRestore(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	t.Run("smoke test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var archive bytes.Buffer
+		_, err := Export(ctx, &memoryBackend{snapshot: testSnapshot()}, "json", createdAt, &archive)
+		require.NoError(t, err)
+		backend := &memoryBackend{}
+
+		// act
+		manifest, err := Restore(ctx, backend, &archive)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, "json", manifest.Backend)
+		assert.Equal(t, testSnapshot(), backend.snapshot)
+	})
+	t.Run("invalid archive test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var archive bytes.Buffer
+		_, err := Export(ctx, &memoryBackend{snapshot: testSnapshot()}, "json", createdAt, &archive)
+		require.NoError(t, err)
+		changed := rewrite(t, archive.Bytes(), func(manifest *Manifest, _ map[string][]byte) { manifest.Entities[0].SHA256 = "" })
+		backend := &memoryBackend{snapshot: &Snapshot{}}
+
+		// act
+		_, err = Restore(ctx, backend, bytes.NewReader(changed))
+
+		// assert
+		assert.Error(t, err)
+		assert.Equal(t, &Snapshot{}, backend.snapshot)
+	})
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,210 @@
+package backup
+
+import (
+	"GOHW-1/internal/db"
+	"GOHW-1/internal/model"
+	"GOHW-1/internal/storage"
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// Backends are names of backends data is exported from and restored to
+var Backends = []string{"json", "postgres"}
+
+// Backend reads and replaces all domain data of a storage
+type Backend interface {
+	Read(ctx context.Context) (*Snapshot, error)
+	Write(ctx context.Context, snapshot *Snapshot) error
+}
+
+type jsonStorage interface {
+	AvailableOrders() ([]storage.OrderDTO, error)
+	RefundedOrders() ([]storage.OrderDTO, error)
+	PickUpPointsRead() ([]model.PickUpPoint, error)
+	Restore(availableOrders []storage.OrderDTO, refundedOrders []storage.OrderDTO, pickUpPoints []model.PickUpPoint) error
+}
+
+// JSONBackend is the JSON storage of the order commands. It keeps no request statistics
+type JSONBackend struct {
+	storage jsonStorage
+}
+
+func NewJSONBackend(storage jsonStorage) *JSONBackend {
+	return &JSONBackend{storage: storage}
+}
+
+func (backend *JSONBackend) Read(_ context.Context) (*Snapshot, error) {
+	pickUpPoints, err := backend.storage.PickUpPointsRead()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read pick-up points: %w", err)
+	}
+	availableOrders, err := backend.storage.AvailableOrders()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read available orders: %w", err)
+	}
+	refundedOrders, err := backend.storage.RefundedOrders()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read refunded orders: %w", err)
+	}
+
+	snapshot := &Snapshot{PickUpPoints: pickUpPoints}
+	for _, order := range availableOrders {
+		state := model.OrderAvailable
+		if order.IsGiven {
+			state = model.OrderGiven
+		}
+		snapshot.Orders = append(snapshot.Orders, order.StoredOrder(state))
+	}
+	for _, order := range refundedOrders {
+		snapshot.Refunds = append(snapshot.Refunds, order.StoredOrder(model.OrderRefunded))
+	}
+	return snapshot, nil
+}
+
+// Write replaces the files with the snapshot, request statistics are skipped
+func (backend *JSONBackend) Write(_ context.Context, snapshot *Snapshot) error {
+	if len(snapshot.RequestStats) > 0 {
+		slog.Warn("the JSON storage keeps no request statistics, they are not restored", "records", len(snapshot.RequestStats))
+	}
+	availableOrders := make([]storage.OrderDTO, 0, len(snapshot.Orders))
+	for _, order := range snapshot.Orders {
+		availableOrders = append(availableOrders, storage.NewOrderDTO(order))
+	}
+	refundedOrders := make([]storage.OrderDTO, 0, len(snapshot.Refunds))
+	for _, order := range snapshot.Refunds {
+		refundedOrders = append(refundedOrders, storage.NewOrderDTO(order))
+	}
+	pickUpPoints := snapshot.PickUpPoints
+	if pickUpPoints == nil {
+		pickUpPoints = []model.PickUpPoint{}
+	}
+	return backend.storage.Restore(availableOrders, refundedOrders, pickUpPoints)
+}
+
+type transactor interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error, options ...db.TxOption) error
+}
+
+type orderRepo interface {
+	Upsert(ctx context.Context, order model.StoredOrder) error
+	List(ctx context.Context) ([]model.StoredOrder, error)
+	DeleteAll(ctx context.Context) error
+}
+
+type pickUpPointRepo interface {
+	Upsert(ctx context.Context, pickUpPoint model.PickUpPoint) error
+	List(ctx context.Context) ([]model.PickUpPoint, error)
+	SyncIDSequence(ctx context.Context) error
+	DeleteAll(ctx context.Context) error
+}
+
+type requestStatsRepo interface {
+	Save(ctx context.Context, stats []model.RouteStats) error
+	All(ctx context.Context) ([]model.RouteStats, error)
+	DeleteAll(ctx context.Context) error
+}
+
+// PostgresBackend is the PostgreSQL database of the http API and imported orders
+type PostgresBackend struct {
+	transactor   transactor
+	orders       orderRepo
+	pickUpPoints pickUpPointRepo
+	requestStats requestStatsRepo
+}
+
+func NewPostgresBackend(transactor transactor, orders orderRepo, pickUpPoints pickUpPointRepo,
+	requestStats requestStatsRepo) *PostgresBackend {
+	return &PostgresBackend{transactor: transactor, orders: orders, pickUpPoints: pickUpPoints, requestStats: requestStats}
+}
+
+// Read reads all tables in one read-only repeatable read transaction, so the snapshot is consistent
+func (backend *PostgresBackend) Read(ctx context.Context) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	err := backend.transactor.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if snapshot.PickUpPoints, err = backend.pickUpPoints.List(ctx); err != nil {
+			return fmt.Errorf("cannot read pick-up points: %w", err)
+		}
+		orders, err := backend.orders.List(ctx)
+		if err != nil {
+			return fmt.Errorf("cannot read orders: %w", err)
+		}
+		snapshot.Orders, snapshot.Refunds = nil, nil
+		for _, order := range orders {
+			if order.State == model.OrderRefunded {
+				snapshot.Refunds = append(snapshot.Refunds, order)
+			} else {
+				snapshot.Orders = append(snapshot.Orders, order)
+			}
+		}
+		if snapshot.RequestStats, err = backend.requestStats.All(ctx); err != nil {
+			return fmt.Errorf("cannot read request statistics: %w", err)
+		}
+		return nil
+	}, db.ReadOnly(), db.WithIsolation(pgx.RepeatableRead))
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Write replaces all tables with the snapshot in one transaction. The orders table keeps one record by ID,
+// so an order that is both refunded and accepted again is restored from orders
+func (backend *PostgresBackend) Write(ctx context.Context, snapshot *Snapshot) error {
+	return backend.transactor.WithTx(ctx, func(ctx context.Context) error {
+		if err := backend.orders.DeleteAll(ctx); err != nil {
+			return fmt.Errorf("cannot delete orders: %w", err)
+		}
+		if err := backend.pickUpPoints.DeleteAll(ctx); err != nil {
+			return fmt.Errorf("cannot delete pick-up points: %w", err)
+		}
+		if err := backend.requestStats.DeleteAll(ctx); err != nil {
+			return fmt.Errorf("cannot delete request statistics: %w", err)
+		}
+
+		for _, pickUpPoint := range snapshot.PickUpPoints {
+			if err := backend.pickUpPoints.Upsert(ctx, pickUpPoint); err != nil {
+				return fmt.Errorf("cannot restore pick-up point %d: %w", pickUpPoint.ID, err)
+			}
+		}
+		if err := backend.pickUpPoints.SyncIDSequence(ctx); err != nil {
+			return fmt.Errorf("cannot update pick-up point IDs: %w", err)
+		}
+		for _, orders := range [][]model.StoredOrder{snapshot.Refunds, snapshot.Orders} {
+			for _, order := range orders {
+				if err := backend.orders.Upsert(ctx, order); err != nil {
+					return fmt.Errorf("cannot restore order %d: %w", order.ID, err)
+				}
+			}
+		}
+		if err := backend.requestStats.Save(ctx, snapshot.RequestStats); err != nil {
+			return fmt.Errorf("cannot restore request statistics: %w", err)
+		}
+		return nil
+	})
+}
+
+// Export writes all data of the backend to w as an archive
+func Export(ctx context.Context, backend Backend, backendName string, createdAt time.Time, w io.Writer) (*Manifest, error) {
+	snapshot, err := backend.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Write(w, backendName, createdAt, snapshot)
+}
+
+// Restore validates the whole archive and only then replaces all data of the backend with it
+func Restore(ctx context.Context, backend Backend, r io.Reader) (*Manifest, error) {
+	manifest, snapshot, err := Read(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := backend.Write(ctx, snapshot); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
@@ -0,0 +1,65 @@
+package backup
+
+import (
+	"GOHW-1/internal/model"
+	"GOHW-1/internal/storage"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type memoryStorage struct {
+	available    []storage.OrderDTO
+	refunded     []storage.OrderDTO
+	pickUpPoints []model.PickUpPoint
+}
+
+func (s *memoryStorage) AvailableOrders() ([]storage.OrderDTO, error) { return s.available, nil }
+
+func (s *memoryStorage) RefundedOrders() ([]storage.OrderDTO, error) { return s.refunded, nil }
+
+func (s *memoryStorage) PickUpPointsRead() ([]model.PickUpPoint, error) { return s.pickUpPoints, nil }
+
+func (s *memoryStorage) Restore(availableOrders []storage.OrderDTO, refundedOrders []storage.OrderDTO,
+	pickUpPoints []model.PickUpPoint) error {
+	s.available, s.refunded, s.pickUpPoints = availableOrders, refundedOrders, pickUpPoints
+	return nil
+}
+
+func TestJSONBackend(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	t.Run("round trip test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		snapshot := testSnapshot()
+		snapshot.RequestStats = nil
+		backend := NewJSONBackend(&memoryStorage{})
+
+		// act
+		err := backend.Write(ctx, snapshot)
+		require.NoError(t, err)
+		read, err := backend.Read(ctx)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, snapshot, read)
+	})
+	t.Run("given order test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		jsonStorage := &memoryStorage{}
+		backend := NewJSONBackend(jsonStorage)
+
+		// act
+		err := backend.Write(ctx, testSnapshot())
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, jsonStorage.available, 2)
+		assert.False(t, jsonStorage.available[0].IsGiven)
+		assert.True(t, jsonStorage.available[1].IsGiven)
+		assert.Equal(t, *testSnapshot().Orders[1].GivenTime, jsonStorage.available[1].GivenTime)
+	})
+}
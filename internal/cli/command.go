@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"GOHW-1/internal/configuration"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Command describes a subcommand. Help, usage errors and shell completion are generated from it,
+// so they always match the flags the command accepts
+type Command struct {
+	Name string
+	// Summary is the one-line description shown in the list of commands
+	Summary string
+	// Description is shown below the summary in the help of the command
+	Description string
+	// Args describes positional arguments in the usage line (e.g. "[command]")
+	Args string
+	// Required lists names of flags that must be given
+	Required []string
+	Example  string
+	// Flags returns the flag set of the command from the config, nil means the command has no flags
+	Flags func(config *configuration.AppConfig) *flag.FlagSet
+	// Complete returns values offered by shell completion for positional arguments
+	Complete func() []string
+	Run      func(config *configuration.AppConfig, args []string) error
+}
+
+// UsageError is returned when a command is unknown or its command line is invalid
+type UsageError struct {
+	Command string
+	Err     error
+}
+
+func (e *UsageError) Error() string {
+	if e.Command == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("invalid usage of %s: %v", e.Command, e.Err)
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// flagSet returns the flag set of the command, commands without flags get an empty one
+func (command Command) flagSet(config *configuration.AppConfig) *flag.FlagSet {
+	if command.Flags == nil {
+		return flag.NewFlagSet(command.Name, flag.ContinueOnError)
+	}
+	return command.Flags(config)
+}
+
+// parse parses args and checks that required flags are given
+func (command Command) parse(flagSet *flag.FlagSet, args []string) error {
+	flagSet.SetOutput(io.Discard)
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &UsageError{Command: command.Name, Err: err}
+	}
+
+	given := make(map[string]bool)
+	flagSet.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+	var missing []string
+	for _, name := range command.Required {
+		if !given[name] {
+			missing = append(missing, "-"+name)
+		}
+	}
+	if len(missing) > 0 {
+		return &UsageError{Command: command.Name, Err: fmt.Errorf("missing required flags: %s", strings.Join(missing, ", "))}
+	}
+	return nil
+}
+
+// usage returns the usage line of the command, e.g. "cl-orders -cid=int [-n=int] [-ouo]"
+func (command Command) usage(flagSet *flag.FlagSet) string {
+	required := make(map[string]bool, len(command.Required))
+	for _, name := range command.Required {
+		required[name] = true
+	}
+
+	parts := []string{command.Name}
+	flagSet.VisitAll(func(f *flag.Flag) {
+		if required[f.Name] {
+			parts = append(parts, flagUsage(f))
+		}
+	})
+	flagSet.VisitAll(func(f *flag.Flag) {
+		if !required[f.Name] {
+			parts = append(parts, "["+flagUsage(f)+"]")
+		}
+	})
+	if command.Args != "" {
+		parts = append(parts, command.Args)
+	}
+	return strings.Join(parts, " ")
+}
+
+func flagUsage(f *flag.Flag) string {
+	if IsBoolFlag(f) {
+		return "-" + f.Name
+	}
+	valueName, _ := flag.UnquoteUsage(f)
+	return "-" + f.Name + "=" + valueName
+}
+
+// IsBoolFlag reports whether the flag can be given without a value
+func IsBoolFlag(f *flag.Flag) bool {
+	boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
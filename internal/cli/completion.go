@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	Bash = "bash"
+	Zsh  = "zsh"
+)
+
+// Shells lists shells supported by WriteCompletion
+var Shells = []string{Bash, Zsh}
+
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// WriteCompletion writes a completion script of the program for the given shell
+func (r *Registry) WriteCompletion(w io.Writer, shell string) error {
+	switch shell {
+	case Bash:
+		r.writeBashCompletion(w)
+	case Zsh:
+		r.writeZshCompletion(w)
+	default:
+		return &UsageError{Err: fmt.Errorf("unknown shell %q: expected one of %s", shell, strings.Join(Shells, ", "))}
+	}
+	return nil
+}
+
+// Candidates returns words offered by completion after the command name: flags and positional values
+func (r *Registry) Candidates(name string) []string {
+	command, ok := r.Lookup(name)
+	if !ok {
+		return nil
+	}
+	flagSet, _ := r.FlagSet(name)
+
+	var candidates []string
+	flagSet.VisitAll(func(f *flag.Flag) {
+		if IsBoolFlag(f) {
+			candidates = append(candidates, "-"+f.Name)
+		} else {
+			candidates = append(candidates, "-"+f.Name+"=")
+		}
+	})
+	if command.Complete != nil {
+		candidates = append(candidates, command.Complete()...)
+	}
+	return candidates
+}
+
+func (r *Registry) writeBashCompletion(w io.Writer) {
+	function := "_" + nonIdentifier.ReplaceAllString(r.program, "_") + "_completion"
+
+	fmt.Fprintf(w, "# bash completion for %s, load it with: source <(%s completion bash)\n", r.program, r.program)
+	fmt.Fprintf(w, "%s() {\n", function)
+	fmt.Fprint(w, "\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	fmt.Fprint(w, "\tif [ \"$COMP_CWORD\" -eq 1 ]; then\n")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(r.Names(), " "))
+	fmt.Fprint(w, "\t\treturn\n\tfi\n")
+	fmt.Fprint(w, "\tcase \"${COMP_WORDS[1]}\" in\n")
+	for _, name := range r.Names() {
+		candidates := r.Candidates(name)
+		if len(candidates) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\t%s) COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n", name, strings.Join(candidates, " "))
+	}
+	fmt.Fprint(w, "\tesac\n")
+	fmt.Fprint(w, "\t[[ \"${COMPREPLY[0]}\" == *= ]] && compopt -o nospace\n")
+	fmt.Fprint(w, "}\n")
+	fmt.Fprintf(w, "complete -F %s %s\n", function, r.program)
+}
+
+func (r *Registry) writeZshCompletion(w io.Writer) {
+	function := "_" + nonIdentifier.ReplaceAllString(r.program, "_")
+
+	fmt.Fprintf(w, "#compdef %s\n", r.program)
+	fmt.Fprintf(w, "# zsh completion for %s, load it with: source <(%s completion zsh)\n", r.program, r.program)
+	fmt.Fprintf(w, "%s() {\n", function)
+	fmt.Fprint(w, "\tlocal -a commands values flags\n")
+	fmt.Fprint(w, "\tcommands=(\n")
+	for _, command := range r.commands {
+		fmt.Fprintf(w, "\t\t%s\n", zshQuote(command.Name+":"+command.Summary))
+	}
+	fmt.Fprint(w, "\t)\n")
+	fmt.Fprint(w, "\tif (( CURRENT == 2 )); then\n\t\t_describe 'command' commands\n\t\treturn\n\tfi\n")
+	fmt.Fprint(w, "\tcase \"$words[2]\" in\n")
+	for _, command := range r.commands {
+		flagSet, _ := r.FlagSet(command.Name)
+		var values, flags []string
+		flagSet.VisitAll(func(f *flag.Flag) {
+			_, usage := flag.UnquoteUsage(f)
+			if IsBoolFlag(f) {
+				flags = append(flags, zshQuote("-"+f.Name+":"+usage))
+			} else {
+				values = append(values, zshQuote("-"+f.Name+"=:"+usage))
+			}
+		})
+		if command.Complete != nil {
+			for _, value := range command.Complete() {
+				flags = append(flags, zshQuote(value))
+			}
+		}
+		if len(values) == 0 && len(flags) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\t%s)\n", command.Name)
+		if len(values) > 0 {
+			fmt.Fprintf(w, "\t\tvalues=(%s)\n\t\t_describe 'flag' values -S ''\n", strings.Join(values, " "))
+		}
+		if len(flags) > 0 {
+			fmt.Fprintf(w, "\t\tflags=(%s)\n\t\t_describe 'argument' flags\n", strings.Join(flags, " "))
+		}
+		fmt.Fprint(w, "\t\t;;\n")
+	}
+	fmt.Fprint(w, "\tesac\n")
+	fmt.Fprint(w, "}\n")
+	fmt.Fprintf(w, "compdef %s %s\n", function, r.program)
+}
+
+// zshQuote quotes a word for zsh with single quotes
+func zshQuote(word string) string {
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
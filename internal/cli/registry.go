@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"GOHW-1/internal/configuration"
+	"GOHW-1/internal/output"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Registry keeps commands in the order they are registered and dispatches command lines to them
+type Registry struct {
+	program  string
+	commands []Command
+}
+
+// NewRegistry returns a registry of the given commands. The program name is used in usage lines,
+// it is empty for commands entered in the interactive mode
+func NewRegistry(program string, commands ...Command) *Registry {
+	registry := &Registry{program: program}
+	registry.Register(commands...)
+	return registry
+}
+
+// Register adds commands to the registry. It panics on duplicate names as that is a programming error
+func (r *Registry) Register(commands ...Command) {
+	for _, command := range commands {
+		if _, ok := r.Lookup(command.Name); ok {
+			panic(fmt.Sprintf("command %q is registered twice", command.Name))
+		}
+		r.commands = append(r.commands, command)
+	}
+}
+
+func (r *Registry) Lookup(name string) (Command, bool) {
+	for _, command := range r.commands {
+		if command.Name == name {
+			return command, true
+		}
+	}
+	return Command{}, false
+}
+
+// Names returns names of all commands in the order of registration
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.commands))
+	for _, command := range r.commands {
+		names = append(names, command.Name)
+	}
+	return names
+}
+
+// FlagSet returns fresh flags of the named command
+func (r *Registry) FlagSet(name string) (*flag.FlagSet, bool) {
+	command, ok := r.Lookup(name)
+	if !ok {
+		return nil, false
+	}
+	config := configuration.NewAppConfig(flag.ContinueOnError)
+	return command.flagSet(&config), true
+}
+
+// Run parses args (a command name followed by its flags) and runs the command.
+// It returns flag.ErrHelp when help of the command is requested with -h or -help
+func (r *Registry) Run(args []string) error {
+	_, _, err := r.run(args)
+	return err
+}
+
+// Execute runs the command line like Run and reports errors to stderr. Errors are written as JSON when
+// the command is asked for -output=json or -output=jsonl. It returns the exit code of the program:
+// 0 on success, 1 when the command fails and 2 when the command line is invalid
+func (r *Registry) Execute(args []string, stdout io.Writer, stderr io.Writer) int {
+	command, flagSet, err := r.run(args)
+	if err == nil {
+		return 0
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		r.printCommandHelp(stdout, command)
+		return 0
+	}
+
+	format := output.Table
+	if flagSet != nil {
+		if outputFlag := flagSet.Lookup("output"); outputFlag != nil {
+			format = outputFlag.Value.String()
+		}
+	}
+	output.PrintError(stderr, format, command.Name, err)
+
+	var usageError *UsageError
+	if !errors.As(err, &usageError) {
+		return 1
+	}
+	if !output.IsStructured(format) {
+		if flagSet == nil {
+			fmt.Fprintln(stderr)
+			r.PrintUsage(stderr)
+		} else {
+			fmt.Fprintf(stderr, "Usage: %s\nRun `%s` for details\n", r.usageLine(command, flagSet),
+				r.withProgram("help "+command.Name))
+		}
+	}
+	return 2
+}
+
+func (r *Registry) run(args []string) (Command, *flag.FlagSet, error) {
+	if len(args) == 0 {
+		return Command{}, nil, &UsageError{Err: errors.New("you need to write a command")}
+	}
+	command, ok := r.Lookup(args[0])
+	if !ok {
+		return Command{Name: args[0]}, nil, &UsageError{Err: fmt.Errorf("unknown command %q", args[0])}
+	}
+
+	config := configuration.NewAppConfig(flag.ContinueOnError)
+	flagSet := command.flagSet(&config)
+	if err := command.parse(flagSet, args[1:]); err != nil {
+		return command, flagSet, err
+	}
+	return command, flagSet, command.Run(&config, flagSet.Args())
+}
+
+// PrintUsage writes the list of commands
+func (r *Registry) PrintUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s\n\nCOMMANDS\n", r.withProgram("<command> [flags]"))
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, command := range r.commands {
+		fmt.Fprintf(writer, "  %s\t%s\n", command.Name, command.Summary)
+	}
+	writer.Flush()
+	fmt.Fprintf(w, "\nRun `%s` for flags of a command\n", r.withProgram("help <command>"))
+}
+
+// PrintHelp writes help of the named command, or the list of commands when the name is empty
+func (r *Registry) PrintHelp(w io.Writer, name string) error {
+	if name == "" {
+		r.PrintUsage(w)
+		return nil
+	}
+	command, ok := r.Lookup(name)
+	if !ok {
+		return &UsageError{Err: fmt.Errorf("unknown command %q", name)}
+	}
+	r.printCommandHelp(w, command)
+	return nil
+}
+
+func (r *Registry) printCommandHelp(w io.Writer, command Command) {
+	config := configuration.NewAppConfig(flag.ContinueOnError)
+	flagSet := command.flagSet(&config)
+
+	fmt.Fprintf(w, "Usage: %s\n\n  %s\n", r.usageLine(command, flagSet), command.Summary)
+	if command.Description != "" {
+		fmt.Fprintf(w, "\n  %s\n", strings.ReplaceAll(command.Description, "\n", "\n  "))
+	}
+
+	required := make(map[string]bool, len(command.Required))
+	for _, name := range command.Required {
+		required[name] = true
+	}
+	hasFlags := false
+	flagSet.VisitAll(func(f *flag.Flag) {
+		if !hasFlags {
+			fmt.Fprint(w, "\nFLAGS\n")
+			hasFlags = true
+		}
+		valueName, usage := flag.UnquoteUsage(f)
+		fmt.Fprintf(w, "  -%s", f.Name)
+		if valueName != "" {
+			fmt.Fprintf(w, " %s", valueName)
+		}
+		fmt.Fprintf(w, "\n    \t%s", usage)
+		if required[f.Name] {
+			fmt.Fprint(w, " (required)")
+		} else if !isZeroValue(f) && valueName == "string" {
+			fmt.Fprintf(w, " (default %q)", f.DefValue)
+		} else if !isZeroValue(f) {
+			fmt.Fprintf(w, " (default %v)", f.DefValue)
+		}
+		fmt.Fprintln(w)
+	})
+
+	if command.Example != "" {
+		fmt.Fprintf(w, "\nExample of using: `%s`\n", r.withProgram(command.Example))
+	}
+}
+
+func (r *Registry) usageLine(command Command, flagSet *flag.FlagSet) string {
+	return r.withProgram(command.usage(flagSet))
+}
+
+func (r *Registry) withProgram(line string) string {
+	if r.program == "" {
+		return line
+	}
+	return r.program + " " + line
+}
+
+func isZeroValue(f *flag.Flag) bool {
+	switch f.DefValue {
+	case "", "0", "false", "0s":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"GOHW-1/internal/configuration"
+	"bytes"
+	"errors"
+	"flag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newTestRegistry(calls *[]string) *Registry {
+	return NewRegistry("gohw",
+		Command{
+			Name:     "cl-orders",
+			Summary:  "Get orders of the client",
+			Required: []string{"cid"},
+			Example:  "cl-orders -cid=1",
+			Flags:    func(config *configuration.AppConfig) *flag.FlagSet { return &config.ClOrders.FlagSet },
+			Run: func(config *configuration.AppConfig, _ []string) error {
+				*calls = append(*calls, "cl-orders")
+				if *config.ClOrders.ClientID == 13 {
+					return errors.New("client is not found")
+				}
+				return nil
+			},
+		},
+		Command{
+			Name:     "completion",
+			Summary:  "Prints a shell completion script",
+			Args:     "bash|zsh",
+			Complete: func() []string { return Shells },
+			Run: func(_ *configuration.AppConfig, _ []string) error {
+				return nil
+			},
+		},
+	)
+}
+
+func TestRegistry_Execute(t *testing.T) {
+	t.Parallel()
+	t.Run("smoke test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var calls []string
+		var stdout, stderr bytes.Buffer
+
+		// act
+		code := newTestRegistry(&calls).Execute([]string{"cl-orders", "-cid=1"}, &stdout, &stderr)
+
+		// assert
+		assert.Equal(t, 0, code)
+		assert.Equal(t, []string{"cl-orders"}, calls)
+		assert.Empty(t, stderr.String())
+	})
+	t.Run("missing required flag test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var calls []string
+		var stdout, stderr bytes.Buffer
+
+		// act
+		code := newTestRegistry(&calls).Execute([]string{"cl-orders", "-n=5"}, &stdout, &stderr)
+
+		// assert
+		assert.Equal(t, 2, code)
+		assert.Empty(t, calls)
+		assert.Contains(t, stderr.String(), "missing required flags: -cid")
+		assert.Contains(t, stderr.String(), "Usage: gohw cl-orders -cid=int [-n=int] [-ouo] [-output=string]")
+	})
+	t.Run("unknown flag test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var calls []string
+		var stdout, stderr bytes.Buffer
+
+		// act
+		code := newTestRegistry(&calls).Execute([]string{"cl-orders", "-x"}, &stdout, &stderr)
+
+		// assert
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr.String(), "flag provided but not defined: -x")
+	})
+	t.Run("unknown command test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var calls []string
+		var stdout, stderr bytes.Buffer
+
+		// act
+		code := newTestRegistry(&calls).Execute([]string{"nothing"}, &stdout, &stderr)
+
+		// assert
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr.String(), `unknown command "nothing"`)
+		assert.Contains(t, stderr.String(), "COMMANDS")
+	})
+	t.Run("failing command with json output test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var calls []string
+		var stdout, stderr bytes.Buffer
+
+		// act
+		code := newTestRegistry(&calls).Execute([]string{"cl-orders", "-cid=13", "-output=json"}, &stdout, &stderr)
+
+		// assert
+		assert.Equal(t, 1, code)
+		assert.Equal(t, `{"command":"cl-orders","error":"client is not found"}`+"\n", stderr.String())
+	})
+	t.Run("help flag test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var calls []string
+		var stdout, stderr bytes.Buffer
+
+		// act
+		code := newTestRegistry(&calls).Execute([]string{"cl-orders", "-h"}, &stdout, &stderr)
+
+		// assert
+		assert.Equal(t, 0, code)
+		assert.Empty(t, calls)
+		assert.Contains(t, stdout.String(), "Client ID (required)")
+		assert.Contains(t, stdout.String(), "How many last orders the user will receive (default -1)")
+		assert.Contains(t, stdout.String(), `(default "table")`)
+	})
+}
+
+func TestRegistry_WriteCompletion(t *testing.T) {
+	t.Parallel()
+	t.Run("bash test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var calls []string
+		var buffer bytes.Buffer
+
+		// act
+		err := newTestRegistry(&calls).WriteCompletion(&buffer, Bash)
+
+		// assert
+		require.NoError(t, err)
+		assert.Contains(t, buffer.String(), `compgen -W "cl-orders completion"`)
+		assert.Contains(t, buffer.String(), `cl-orders) COMPREPLY=($(compgen -W "-cid= -n= -ouo -output="`)
+		assert.Contains(t, buffer.String(), `completion) COMPREPLY=($(compgen -W "bash zsh"`)
+		assert.Contains(t, buffer.String(), "complete -F _gohw_completion gohw")
+	})
+	t.Run("zsh test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var calls []string
+		var buffer bytes.Buffer
+
+		// act
+		err := newTestRegistry(&calls).WriteCompletion(&buffer, Zsh)
+
+		// assert
+		require.NoError(t, err)
+		assert.Contains(t, buffer.String(), "#compdef gohw")
+		assert.Contains(t, buffer.String(), "'cl-orders:Get orders of the client'")
+		assert.Contains(t, buffer.String(), "'-cid=:Client ID'")
+		assert.Contains(t, buffer.String(), "'-ouo:Get only user orders'")
+	})
+	t.Run("unknown shell test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var calls []string
+		var buffer bytes.Buffer
+
+		// act
+		err := newTestRegistry(&calls).WriteCompletion(&buffer, "fish")
+
+		// assert
+		var usageError *UsageError
+		require.ErrorAs(t, err, &usageError)
+	})
+}
@@ -0,0 +1,53 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time. Business rules read it instead of calling time.Now, so that they can be tested
+// exactly and incidents can be reproduced at a given moment
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// System returns the clock of the operating system
+func System() Clock {
+	return systemClock{}
+}
+
+// Fake is a clock that stands still until it is set or advanced. It is used in tests and by the -now flag
+type Fake struct {
+	now   time.Time
+	mutex sync.Mutex
+}
+
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+func (fake *Fake) Now() time.Time {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	return fake.now
+}
+
+// Set moves the clock to the given time
+func (fake *Fake) Set(now time.Time) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	fake.now = now
+}
+
+// Advance moves the clock forward by d
+func (fake *Fake) Advance(d time.Duration) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	fake.now = fake.now.Add(d)
+}
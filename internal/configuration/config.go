@@ -2,17 +2,40 @@ package configuration
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"strconv"
 	"time"
 )
 
+// OutputUsage describes the -output flag of listing commands
+const OutputUsage = "Output format: table, json, jsonl or csv"
+
+// BackendUsage describes the -backend flag of export and restore
+const BackendUsage = "Storage of the data: json (files of order commands) or postgres (database of the http API)"
+
 type AppConfig struct {
-	CrTake     CrTakeConfig
-	CrReturn   CrReturnConfig
-	ClGive     ClGiveConfig
-	ClOrders   ClOrdersConfig
-	ClRefund   ClRefundConfig
-	RefundList RefundListConfig
+	HTTP        HTTPCommandConfig
+	Interactive InteractiveConfig
+	CrTake      CrTakeConfig
+	CrReturn    CrReturnConfig
+	ClGive      ClGiveConfig
+	ClOrders    ClOrdersConfig
+	ClRefund    ClRefundConfig
+	RefundList  RefundListConfig
+	Import      ImportConfig
+	Export      BackupConfig
+	Restore     BackupConfig
+}
+
+type HTTPCommandConfig struct {
+	FlagSet flag.FlagSet
+	Migrate *bool
+}
+
+type InteractiveConfig struct {
+	FlagSet        flag.FlagSet
+	MetricsAddress *string
 }
 
 type CrTakeConfig struct {
@@ -23,6 +46,7 @@ type CrTakeConfig struct {
 	Weight        *float64
 	Price         *float64
 	Packaging     *string
+	PickUpPointID *int64
 }
 
 type CrReturnConfig struct {
@@ -41,6 +65,7 @@ type ClOrdersConfig struct {
 	ClientID       *int
 	N              *int
 	OnlyUserOrders *bool
+	Output         *string
 }
 
 type ClRefundConfig struct {
@@ -52,14 +77,33 @@ type ClRefundConfig struct {
 type RefundListConfig struct {
 	FlagSet    flag.FlagSet
 	PageNumber *int
+	Output     *string
+}
+
+type ImportConfig struct {
+	FlagSet flag.FlagSet
+	DryRun  *bool
+	Output  *string
+}
+
+type BackupConfig struct {
+	FlagSet flag.FlagSet
+	Backend *string
 }
 
 type DBCredentials struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBname   string
+	Host     string `yaml:"host" env:"POSTGRES_HOST" usage:"PostgreSQL host"`
+	Port     string `yaml:"port" env:"POSTGRES_PORT" usage:"PostgreSQL port"`
+	User     string `yaml:"user" env:"POSTGRES_USER" usage:"PostgreSQL user"`
+	Password string `yaml:"password" env:"POSTGRES_PASSWORD" usage:"PostgreSQL password" secret:"true"`
+	DBname   string `yaml:"dbname" env:"POSTGRES_DB" usage:"PostgreSQL database"`
+
+	SSLMode     string `yaml:"sslmode" env:"POSTGRES_SSLMODE" usage:"disable, allow, prefer, require, verify-ca or verify-full"`
+	SSLRootCert string `yaml:"sslrootcert" env:"POSTGRES_SSLROOTCERT" usage:"CA certificate to verify the server"`
+	SSLCert     string `yaml:"sslcert" env:"POSTGRES_SSLCERT" usage:"Client certificate"`
+	SSLKey      string `yaml:"sslkey" env:"POSTGRES_SSLKEY" usage:"Client key"`
+
+	Pool PoolConfig `yaml:"pool"`
 }
 
 func NewDBCredentials() *DBCredentials {
@@ -74,6 +118,20 @@ func (dbCredentials *DBCredentials) SetEnv() {
 	dbCredentials.DBname = os.Getenv("POSTGRES_DB")
 }
 
+// Validate checks that connection settings are given
+func (dbCredentials *DBCredentials) Validate() error {
+	if dbCredentials.Host == "" || dbCredentials.User == "" || dbCredentials.DBname == "" {
+		return fmt.Errorf("host, user or database is not given")
+	}
+	if _, err := strconv.Atoi(dbCredentials.Port); err != nil {
+		return fmt.Errorf("port must be a number, got %q", dbCredentials.Port)
+	}
+	if err := dbCredentials.validateSSL(); err != nil {
+		return err
+	}
+	return dbCredentials.Pool.Validate()
+}
+
 func (dbCredentials *DBCredentials) SetCredits(host string, port string, user string, password string, dbname string) {
 	dbCredentials.Host = host
 	dbCredentials.Port = port
@@ -86,55 +144,66 @@ func (dbCredentials *DBCredentials) SetDBname(dbname string) {
 	dbCredentials.DBname = dbname
 }
 
-func GetBrokers() *[]string {
-	brokers := []string{
-		"127.0.0.1:9091",
-		"127.0.0.1:9092",
-		"127.0.0.1:9093",
-	}
-	return &brokers
-}
+// NewAppConfig returns fresh command line flags of every command with the given error handling
+func NewAppConfig(errorHandling flag.ErrorHandling) AppConfig {
+	httpCommand := flag.NewFlagSet("http", errorHandling)
+	httpMigrate := httpCommand.Bool("migrate", false, "Apply database migrations before starting the server")
 
-func GetTopicName() *string {
-	topicName := "logs"
-	return &topicName
-}
+	interactive := flag.NewFlagSet("interactive", errorHandling)
+	interactiveMetricsAddress := interactive.String("metrics-addr", "", "Address to serve /metrics on while the mode runs (e.g. :9090, empty - do not serve)")
 
-func DefaultConfig() AppConfig {
-	crTake := flag.NewFlagSet("cr-take", flag.ExitOnError)
+	crTake := flag.NewFlagSet("cr-take", errorHandling)
 	crTakeOrderID := crTake.Int("oid", 0, "Order ID")
 	crTakeClientID := crTake.Int("cid", 0, "Client ID")
 	crTakeAvailableTime := crTake.Duration("at", 0, "Available time for picking up the order (e.g. -at=48h)")
 	crTakeWeight := crTake.Float64("w", 0, "Weight")
 	crTakePrice := crTake.Float64("pr", 0, "Price")
 	crTakePackaging := crTake.String("pg", "", "Packaging")
+	crTakePickUpPointID := crTake.Int64("pp", 0, "Pick-up point ID, its policy applies to the order (0 - default policy)")
 
-	crReturn := flag.NewFlagSet("cr-return", flag.ExitOnError)
+	crReturn := flag.NewFlagSet("cr-return", errorHandling)
 	crReturnOrderID := crReturn.Int("oid", 0, "Order ID")
 
-	clGive := flag.NewFlagSet("cl-give", flag.ExitOnError)
+	clGive := flag.NewFlagSet("cl-give", errorHandling)
 	clGiveClientID := clGive.Int("cid", 0, "Client ID")
 	clGiveOrdersID := clGive.String("oids", "", "Comma-separated slice of ordersID (e.g. -oids=1,3,7)")
 
-	clOrders := flag.NewFlagSet("cl-orders", flag.ExitOnError)
+	clOrders := flag.NewFlagSet("cl-orders", errorHandling)
 	clOrdersClientID := clOrders.Int("cid", 0, "Client ID")
 	clOrdersN := clOrders.Int("n", -1, "How many last orders the user will receive")
 	clOrdersOnlyUserOrders := clOrders.Bool("ouo", false, "Get only user orders")
+	clOrdersOutput := clOrders.String("output", "table", OutputUsage)
 
-	clRefund := flag.NewFlagSet("cl-refund", flag.ExitOnError)
+	clRefund := flag.NewFlagSet("cl-refund", errorHandling)
 	clRefundOrderID := clRefund.Int("oid", 0, "Order ID")
 	clRefundClientID := clRefund.Int("cid", 0, "Client ID")
 
-	refundList := flag.NewFlagSet("refund-list", flag.ExitOnError)
+	refundList := flag.NewFlagSet("refund-list", errorHandling)
 	refundListPageNumber := refundList.Int("p", 1, "Page number starting with 1")
+	refundListOutput := refundList.String("output", "table", OutputUsage)
+
+	importCommand := flag.NewFlagSet("import", errorHandling)
+	importDryRun := importCommand.Bool("dry-run", false, "Roll the import back and only print the report")
+	importOutput := importCommand.String("output", "table", OutputUsage)
+
+	exportCommand := flag.NewFlagSet("export", errorHandling)
+	exportBackend := exportCommand.String("backend", "json", BackendUsage)
+
+	restoreCommand := flag.NewFlagSet("restore", errorHandling)
+	restoreBackend := restoreCommand.String("backend", "json", BackendUsage)
 
 	return AppConfig{
+		HTTP:        HTTPCommandConfig{FlagSet: *httpCommand, Migrate: httpMigrate},
+		Interactive: InteractiveConfig{FlagSet: *interactive, MetricsAddress: interactiveMetricsAddress},
 		CrTake: CrTakeConfig{FlagSet: *crTake, OrderID: crTakeOrderID, ClientID: crTakeClientID, AvailableTime: crTakeAvailableTime,
-			Weight: crTakeWeight, Price: crTakePrice, Packaging: crTakePackaging},
+			Weight: crTakeWeight, Price: crTakePrice, Packaging: crTakePackaging, PickUpPointID: crTakePickUpPointID},
 		CrReturn:   CrReturnConfig{FlagSet: *crReturn, OrderID: crReturnOrderID},
 		ClGive:     ClGiveConfig{FlagSet: *clGive, ClientID: clGiveClientID, OrdersID: clGiveOrdersID},
-		ClOrders:   ClOrdersConfig{FlagSet: *clOrders, ClientID: clOrdersClientID, N: clOrdersN, OnlyUserOrders: clOrdersOnlyUserOrders},
+		ClOrders:   ClOrdersConfig{FlagSet: *clOrders, ClientID: clOrdersClientID, N: clOrdersN, OnlyUserOrders: clOrdersOnlyUserOrders, Output: clOrdersOutput},
 		ClRefund:   ClRefundConfig{FlagSet: *clRefund, OrderID: clRefundOrderID, ClientID: clRefundClientID},
-		RefundList: RefundListConfig{FlagSet: *crTake, PageNumber: refundListPageNumber},
+		RefundList: RefundListConfig{FlagSet: *refundList, PageNumber: refundListPageNumber, Output: refundListOutput},
+		Import:     ImportConfig{FlagSet: *importCommand, DryRun: importDryRun, Output: importOutput},
+		Export:     BackupConfig{FlagSet: *exportCommand, Backend: exportBackend},
+		Restore:    BackupConfig{FlagSet: *restoreCommand, Backend: restoreBackend},
 	}
 }
@@ -0,0 +1,164 @@
+package configuration
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redacted = "<redacted>"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setting is a leaf field of a settings struct. Its key is the path of yaml tags (e.g. "kafka.sasl.user"),
+// used in config files and flags, env is the environment variable from the env tag
+type setting struct {
+	key    string
+	env    string
+	usage  string
+	secret bool
+	value  reflect.Value
+}
+
+// fileOnly reports whether the setting is a map, which is only read from the config file
+func (s setting) fileOnly() bool {
+	return s.value.Kind() == reflect.Map
+}
+
+// settingsOf returns leaf settings of the struct pointed to by config
+func settingsOf(config any) []setting {
+	return collectSettings(reflect.ValueOf(config).Elem(), "", nil)
+}
+
+func collectSettings(value reflect.Value, prefix string, settings []setting) []setting {
+	for i := 0; i < value.NumField(); i++ {
+		structField := value.Type().Field(i)
+		name, _, _ := strings.Cut(structField.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		field := value.Field(i)
+		if field.Kind() == reflect.Struct {
+			settings = collectSettings(field, key, settings)
+			continue
+		}
+		settings = append(settings, setting{
+			key:    key,
+			env:    structField.Tag.Get("env"),
+			usage:  structField.Tag.Get("usage"),
+			secret: structField.Tag.Get("secret") == "true",
+			value:  field,
+		})
+	}
+	return settings
+}
+
+// applyEnv sets fields that have an env tag from the variables found by lookup
+func applyEnv(config any, lookup func(key string) (string, bool)) error {
+	for _, s := range settingsOf(config) {
+		if s.env == "" {
+			continue
+		}
+		if value, ok := lookup(s.env); ok {
+			if err := s.set(s.env, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// set parses the raw value into the field, name is used in errors
+func (s setting) set(name string, raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case s.value.Type() == durationType:
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%s must be a duration: %w", name, err)
+		}
+		s.value.SetInt(int64(parsed))
+	case s.value.Kind() == reflect.String:
+		s.value.SetString(raw)
+	case s.value.Kind() == reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%s must be a boolean: %w", name, err)
+		}
+		s.value.SetBool(parsed)
+	case s.value.Kind() == reflect.Int:
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%s must be an integer: %w", name, err)
+		}
+		s.value.SetInt(int64(parsed))
+	case s.value.Kind() == reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%s must be a number: %w", name, err)
+		}
+		s.value.SetFloat(parsed)
+	case s.value.Kind() == reflect.Slice && s.value.Type().Elem().Kind() == reflect.String:
+		s.value.Set(reflect.ValueOf(splitList(raw)))
+	case s.fileOnly():
+		return fmt.Errorf("%s can only be set in the config file", name)
+	default:
+		return fmt.Errorf("%s has unsupported type %s", name, s.value.Type())
+	}
+	return nil
+}
+
+// String formats the value the way it is accepted by set
+func (s setting) String() string {
+	switch {
+	case s.value.Type() == durationType:
+		return time.Duration(s.value.Int()).String()
+	case s.value.Kind() == reflect.Slice:
+		items := make([]string, 0, s.value.Len())
+		for i := 0; i < s.value.Len(); i++ {
+			items = append(items, s.value.Index(i).String())
+		}
+		return strings.Join(items, ",")
+	case s.fileOnly():
+		return flowYAML(s.value.Interface())
+	default:
+		return fmt.Sprint(s.value.Interface())
+	}
+}
+
+// shown returns the value for printing, secrets that are set are redacted
+func (s setting) shown() string {
+	if s.secret && !s.value.IsZero() {
+		return redacted
+	}
+	return s.String()
+}
+
+// flowYAML formats the value as one line of YAML, e.g. "{1: {refund_window: 24h}}"
+func flowYAML(value any) string {
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	setFlowStyle(node)
+	raw, err := yaml.Marshal(node)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+func setFlowStyle(node *yaml.Node) {
+	node.Style |= yaml.FlowStyle
+	for _, child := range node.Content {
+		setFlowStyle(child)
+	}
+}
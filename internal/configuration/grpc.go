@@ -0,0 +1,28 @@
+package configuration
+
+import (
+	"fmt"
+	"net"
+)
+
+// GRPCConfig holds settings of the gRPC server started by the http command. Credentials of requests
+// are checked against the http section, TLS uses its certificate and key
+type GRPCConfig struct {
+	Address  string `yaml:"address" env:"GRPC_ADDRESS" usage:"Address of the gRPC server (e.g. :9002, empty - do not serve)"`
+	Insecure bool   `yaml:"insecure" env:"GRPC_INSECURE" usage:"Serve gRPC without TLS, e.g. behind a proxy terminating it"`
+}
+
+func NewGRPCConfig() *GRPCConfig {
+	return &GRPCConfig{}
+}
+
+// Validate checks that the address has a port
+func (grpcConfig *GRPCConfig) Validate() error {
+	if grpcConfig.Address == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(grpcConfig.Address); err != nil {
+		return fmt.Errorf("invalid address %q: %w", grpcConfig.Address, err)
+	}
+	return nil
+}
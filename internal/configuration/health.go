@@ -0,0 +1,39 @@
+package configuration
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Dependencies reported by the health endpoints
+const (
+	DependencyPostgres      = "postgres"
+	DependencyKafkaProducer = "kafka_producer"
+	DependencyKafkaConsumer = "kafka_consumer"
+	DependencyStorage       = "storage"
+)
+
+// HealthConfig chooses dependencies the server cannot work without
+type HealthConfig struct {
+	Critical []string `yaml:"critical" env:"HEALTH_CRITICAL" usage:"Dependencies failing /readyz: postgres, kafka_producer, kafka_consumer, storage"`
+}
+
+// NewHealthConfig returns health settings where only PostgreSQL is critical, as requests cannot be served without it
+func NewHealthConfig() *HealthConfig {
+	return &HealthConfig{Critical: []string{DependencyPostgres}}
+}
+
+// IsCritical reports whether a failure of the dependency makes the server not ready
+func (healthConfig *HealthConfig) IsCritical(dependency string) bool {
+	return slices.Contains(healthConfig.Critical, dependency)
+}
+
+// Validate checks that critical dependencies are known
+func (healthConfig *HealthConfig) Validate() error {
+	for _, critical := range healthConfig.Critical {
+		if !oneOf(critical, DependencyPostgres, DependencyKafkaProducer, DependencyKafkaConsumer, DependencyStorage) {
+			return fmt.Errorf("unknown critical dependency %q: expected postgres, kafka_producer, kafka_consumer or storage", critical)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package configuration
+
+import "fmt"
+
+type HTTPConfig struct {
+	SecurePort   string `yaml:"secure_port" env:"HTTP_SECURE_PORT" usage:"Address of the HTTPS server"`
+	InsecurePort string `yaml:"insecure_port" env:"HTTP_INSECURE_PORT" usage:"Address of the HTTP server redirecting to HTTPS"`
+	CertFile     string `yaml:"cert_file" env:"HTTP_CERT_FILE" usage:"TLS certificate of the server"`
+	KeyFile      string `yaml:"key_file" env:"HTTP_KEY_FILE" usage:"TLS key of the server"`
+	Username     string `yaml:"username" env:"HTTP_USERNAME" usage:"Basic auth user"`
+	Password     string `yaml:"password" env:"HTTP_PASSWORD" usage:"Basic auth password" secret:"true"`
+	MaxBodySize  int    `yaml:"max_body_size" env:"HTTP_MAX_BODY_SIZE" usage:"Largest request body in bytes (0 - no limit)"`
+}
+
+// NewHTTPConfig returns http server settings used before they became configurable
+func NewHTTPConfig() *HTTPConfig {
+	return &HTTPConfig{
+		SecurePort:   ":9001",
+		InsecurePort: ":9000",
+		CertFile:     "./server.crt",
+		KeyFile:      "./server.key",
+		Username:     "ildus",
+		Password:     "erbaev",
+		MaxBodySize:  1 << 20,
+	}
+}
+
+// Validate checks that the server addresses and credentials are given
+func (httpConfig *HTTPConfig) Validate() error {
+	if httpConfig.SecurePort == "" || httpConfig.InsecurePort == "" {
+		return fmt.Errorf("http ports are not given")
+	}
+	if httpConfig.SecurePort == httpConfig.InsecurePort {
+		return fmt.Errorf("http secure and insecure ports must differ")
+	}
+	if httpConfig.CertFile == "" || httpConfig.KeyFile == "" {
+		return fmt.Errorf("http cert and key files are not given")
+	}
+	if httpConfig.Username == "" || httpConfig.Password == "" {
+		return fmt.Errorf("http username or password is not given")
+	}
+	if httpConfig.MaxBodySize < 0 {
+		return fmt.Errorf("http max body size must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package configuration
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const kafkaConfigFileEnv = "KAFKA_CONFIG_FILE"
+
+type KafkaConfig struct {
+	Brokers        []string        `yaml:"brokers" env:"KAFKA_BROKERS" usage:"Comma-separated list of brokers"`
+	Topic          string          `yaml:"topic" env:"KAFKA_TOPIC" usage:"Topic for request logs"`
+	GroupID        string          `yaml:"group_id" env:"KAFKA_GROUP_ID" usage:"Consumer group ID"`
+	ClientID       string          `yaml:"client_id" env:"KAFKA_CLIENT_ID" usage:"Client ID sent to brokers"`
+	Version        string          `yaml:"version" env:"KAFKA_VERSION" usage:"Kafka protocol version (empty - latest supported)"`
+	Acks           string          `yaml:"acks" env:"KAFKA_ACKS" usage:"none, leader or all"`
+	Compression    string          `yaml:"compression" env:"KAFKA_COMPRESSION" usage:"none, gzip, snappy, lz4 or zstd"`
+	Partitioner    string          `yaml:"partitioner" env:"KAFKA_PARTITIONER" usage:"round-robin, hash or random"`
+	BatchSize      int             `yaml:"batch_size" env:"KAFKA_BATCH_SIZE" usage:"Messages per batch (0 - send immediately)"`
+	BatchBytes     int             `yaml:"batch_bytes" env:"KAFKA_BATCH_BYTES" usage:"Bytes per batch (0 - send immediately)"`
+	FlushFrequency time.Duration   `yaml:"flush_frequency" env:"KAFKA_FLUSH_FREQUENCY" usage:"Maximum batch delay"`
+	InitialOffset  string          `yaml:"initial_offset" env:"KAFKA_INITIAL_OFFSET" usage:"newest or oldest"`
+	SASL           KafkaSASLConfig `yaml:"sasl"`
+	TLS            KafkaTLSConfig  `yaml:"tls"`
+
+	// Undeliverable events are kept in BufferDir (empty - disabled) and replayed every BufferRetryInterval
+	BufferDir           string        `yaml:"buffer_dir" env:"KAFKA_BUFFER_DIR" usage:"Disk buffer for undelivered events (empty - off)"`
+	BufferMaxEvents     int           `yaml:"buffer_max_events" env:"KAFKA_BUFFER_MAX_EVENTS" usage:"Buffer limit, further events are rejected"`
+	BufferRetryInterval time.Duration `yaml:"buffer_retry_interval" env:"KAFKA_BUFFER_RETRY_INTERVAL" usage:"How often kafka is retried to replay the buffer"`
+}
+
+type KafkaSASLConfig struct {
+	Enabled   bool   `yaml:"enabled" env:"KAFKA_SASL_ENABLED" usage:"Enables SASL authentication"`
+	Mechanism string `yaml:"mechanism" env:"KAFKA_SASL_MECHANISM" usage:"PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512"`
+	User      string `yaml:"user" env:"KAFKA_SASL_USER" usage:"SASL user"`
+	Password  string `yaml:"password" env:"KAFKA_SASL_PASSWORD" usage:"SASL password" secret:"true"`
+}
+
+type KafkaTLSConfig struct {
+	Enabled            bool   `yaml:"enabled" env:"KAFKA_TLS_ENABLED" usage:"Enables TLS"`
+	CAFile             string `yaml:"ca_file" env:"KAFKA_TLS_CA_FILE" usage:"CA certificate"`
+	CertFile           string `yaml:"cert_file" env:"KAFKA_TLS_CERT_FILE" usage:"Client certificate"`
+	KeyFile            string `yaml:"key_file" env:"KAFKA_TLS_KEY_FILE" usage:"Client key"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" env:"KAFKA_TLS_INSECURE_SKIP_VERIFY" usage:"Skips broker certificate verification"`
+}
+
+// NewKafkaConfig returns kafka settings with defaults matching docker-compose.yml
+func NewKafkaConfig() *KafkaConfig {
+	return &KafkaConfig{
+		Brokers: []string{
+			"127.0.0.1:9091",
+			"127.0.0.1:9092",
+			"127.0.0.1:9093",
+		},
+		Topic:         "logs",
+		GroupID:       "route",
+		ClientID:      "gohw",
+		Acks:          "all",
+		Compression:   "none",
+		Partitioner:   "round-robin",
+		InitialOffset: "newest",
+		SASL:          KafkaSASLConfig{Mechanism: "PLAIN"},
+
+		BufferDir:           "kafka_buffer",
+		BufferMaxEvents:     10000,
+		BufferRetryInterval: 5 * time.Second,
+	}
+}
+
+// LoadKafkaConfig reads kafka settings from the file given in KAFKA_CONFIG_FILE (if any),
+// overrides them with environment variables and validates the result
+func LoadKafkaConfig() (*KafkaConfig, error) {
+	kafkaConfig := NewKafkaConfig()
+	if fileName := os.Getenv(kafkaConfigFileEnv); fileName != "" {
+		if err := kafkaConfig.SetFile(fileName); err != nil {
+			return nil, err
+		}
+	}
+	if err := kafkaConfig.SetEnv(); err != nil {
+		return nil, err
+	}
+	if err := kafkaConfig.Validate(); err != nil {
+		return nil, err
+	}
+	return kafkaConfig, nil
+}
+
+// SetFile applies settings from a file of KEY=VALUE lines (the same format as .local.env)
+func (kafkaConfig *KafkaConfig) SetFile(fileName string) error {
+	values, err := readEnvFile(fileName)
+	if err != nil {
+		return err
+	}
+	return kafkaConfig.apply(func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	})
+}
+
+// SetEnv applies settings from KAFKA_* environment variables
+func (kafkaConfig *KafkaConfig) SetEnv() error {
+	return kafkaConfig.apply(os.LookupEnv)
+}
+
+func (kafkaConfig *KafkaConfig) apply(lookup func(key string) (string, bool)) error {
+	return applyEnv(kafkaConfig, lookup)
+}
+
+// Validate checks that kafka settings are complete and consistent
+func (kafkaConfig *KafkaConfig) Validate() error {
+	if len(kafkaConfig.Brokers) == 0 {
+		return fmt.Errorf("kafka brokers are not given")
+	}
+	if kafkaConfig.Topic == "" {
+		return fmt.Errorf("kafka topic is not given")
+	}
+	if kafkaConfig.GroupID == "" {
+		return fmt.Errorf("kafka group id is not given")
+	}
+	if !oneOf(kafkaConfig.Acks, "none", "leader", "all") {
+		return fmt.Errorf("invalid kafka acks %q: expected none, leader or all", kafkaConfig.Acks)
+	}
+	if !oneOf(kafkaConfig.Compression, "none", "gzip", "snappy", "lz4", "zstd") {
+		return fmt.Errorf("invalid kafka compression %q: expected none, gzip, snappy, lz4 or zstd", kafkaConfig.Compression)
+	}
+	if !oneOf(kafkaConfig.Partitioner, "round-robin", "hash", "random") {
+		return fmt.Errorf("invalid kafka partitioner %q: expected round-robin, hash or random", kafkaConfig.Partitioner)
+	}
+	if !oneOf(kafkaConfig.InitialOffset, "newest", "oldest") {
+		return fmt.Errorf("invalid kafka initial offset %q: expected newest or oldest", kafkaConfig.InitialOffset)
+	}
+	if kafkaConfig.BatchSize < 0 || kafkaConfig.BatchBytes < 0 || kafkaConfig.FlushFrequency < 0 {
+		return fmt.Errorf("kafka batching settings must not be negative")
+	}
+	if kafkaConfig.BufferDir != "" && (kafkaConfig.BufferMaxEvents <= 0 || kafkaConfig.BufferRetryInterval <= 0) {
+		return fmt.Errorf("kafka buffer max events and retry interval must be positive")
+	}
+	if kafkaConfig.SASL.Enabled {
+		if !oneOf(kafkaConfig.SASL.Mechanism, "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512") {
+			return fmt.Errorf("invalid kafka sasl mechanism %q: expected PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512", kafkaConfig.SASL.Mechanism)
+		}
+		if kafkaConfig.SASL.User == "" || kafkaConfig.SASL.Password == "" {
+			return fmt.Errorf("kafka sasl is enabled but user or password is not given")
+		}
+	}
+	if kafkaConfig.TLS.Enabled && (kafkaConfig.TLS.CertFile == "") != (kafkaConfig.TLS.KeyFile == "") {
+		return fmt.Errorf("kafka tls cert and key files must be given together")
+	}
+	return nil
+}
+
+// readEnvFile parses KEY=VALUE lines, skipping blank lines and # comments
+func readEnvFile(fileName string) (map[string]string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open config file: %w", err)
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", fileName, lineNumber)
+		}
+		values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func oneOf(value string, options ...string) bool {
+	for _, option := range options {
+		if value == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package configuration
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKafkaConfig_SetFile(t *testing.T) {
+	t.Parallel()
+	t.Run("smoke test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		fileName := filepath.Join(t.TempDir(), "kafka.env")
+		content := "# kafka\nKAFKA_BROKERS=kafka1:9092, kafka2:9092\nKAFKA_TOPIC=events\nKAFKA_ACKS=leader\n" +
+			"KAFKA_FLUSH_FREQUENCY=500ms\nKAFKA_SASL_ENABLED=true\nKAFKA_SASL_USER=user\nKAFKA_SASL_PASSWORD=\"secret\"\n"
+		require.NoError(t, os.WriteFile(fileName, []byte(content), 0600))
+		kafkaConfig := NewKafkaConfig()
+
+		// act
+		err := kafkaConfig.SetFile(fileName)
+
+		// assert
+		require.NoError(t, err)
+		require.NoError(t, kafkaConfig.Validate())
+		assert.Equal(t, []string{"kafka1:9092", "kafka2:9092"}, kafkaConfig.Brokers)
+		assert.Equal(t, "events", kafkaConfig.Topic)
+		assert.Equal(t, "route", kafkaConfig.GroupID)
+		assert.Equal(t, "leader", kafkaConfig.Acks)
+		assert.Equal(t, 500*time.Millisecond, kafkaConfig.FlushFrequency)
+		assert.Equal(t, "secret", kafkaConfig.SASL.Password)
+	})
+	t.Run("malformed line test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		fileName := filepath.Join(t.TempDir(), "kafka.env")
+		require.NoError(t, os.WriteFile(fileName, []byte("KAFKA_TOPIC\n"), 0600))
+		kafkaConfig := NewKafkaConfig()
+
+		// act
+		err := kafkaConfig.SetFile(fileName)
+
+		// assert
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "expected KEY=VALUE")
+	})
+	t.Run("non-integer batch size test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		fileName := filepath.Join(t.TempDir(), "kafka.env")
+		require.NoError(t, os.WriteFile(fileName, []byte("KAFKA_BATCH_SIZE=many\n"), 0600))
+		kafkaConfig := NewKafkaConfig()
+
+		// act
+		err := kafkaConfig.SetFile(fileName)
+
+		// assert
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "KAFKA_BATCH_SIZE must be an integer")
+	})
+}
+
+func TestKafkaConfig_Validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		modify  func(kafkaConfig *KafkaConfig)
+		wantErr string
+	}{
+		{
+			name:   "defaults test",
+			modify: func(kafkaConfig *KafkaConfig) {},
+		},
+		{
+			name:    "no brokers test",
+			modify:  func(kafkaConfig *KafkaConfig) { kafkaConfig.Brokers = nil },
+			wantErr: "kafka brokers are not given",
+		},
+		{
+			name:    "invalid acks test",
+			modify:  func(kafkaConfig *KafkaConfig) { kafkaConfig.Acks = "some" },
+			wantErr: "invalid kafka acks \"some\": expected none, leader or all",
+		},
+		{
+			name: "sasl without password test",
+			modify: func(kafkaConfig *KafkaConfig) {
+				kafkaConfig.SASL.Enabled = true
+				kafkaConfig.SASL.User = "user"
+			},
+			wantErr: "kafka sasl is enabled but user or password is not given",
+		},
+		{
+			name: "tls cert without key test",
+			modify: func(kafkaConfig *KafkaConfig) {
+				kafkaConfig.TLS.Enabled = true
+				kafkaConfig.TLS.CertFile = "client.crt"
+			},
+			wantErr: "kafka tls cert and key files must be given together",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// arrange
+			kafkaConfig := NewKafkaConfig()
+			tt.modify(kafkaConfig)
+
+			// act
+			err := kafkaConfig.Validate()
+
+			// assert
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package configuration
+
+import (
+	"GOHW-1/internal/logger"
+	"fmt"
+)
+
+// LogConfig holds the format of logs, their level is a runtime setting
+type LogConfig struct {
+	Format string `yaml:"format" env:"LOG_FORMAT" usage:"Format of logs: text or json"`
+}
+
+func NewLogConfig() *LogConfig {
+	return &LogConfig{Format: logger.FormatText}
+}
+
+// Validate checks the log format
+func (logConfig *LogConfig) Validate() error {
+	if logConfig.Format != logger.FormatText && logConfig.Format != logger.FormatJSON {
+		return fmt.Errorf("unknown format %q: expected text or json", logConfig.Format)
+	}
+	return nil
+}
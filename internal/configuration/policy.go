@@ -0,0 +1,106 @@
+package configuration
+
+import (
+	"GOHW-1/internal/model"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PolicyConfig holds business rules of orders, PickUpPoints override them for single pick-up points by ID
+type PolicyConfig struct {
+	RefundWindow time.Duration `yaml:"refund_window" env:"REFUND_WINDOW" usage:"How long after giving an order it can be refunded"`
+	PageSize     int           `yaml:"page_size" env:"PAGE_SIZE" usage:"Orders on a page of the refund list"`
+	MaxStorage   time.Duration `yaml:"max_storage" env:"MAX_STORAGE" usage:"Longest time an order can be stored (0 - no limit)"`
+	PickUpGrace  time.Duration `yaml:"pick_up_grace" env:"PICK_UP_GRACE" usage:"How long after the expiration date the client can still receive the order"`
+	ReturnGrace  time.Duration `yaml:"return_grace" env:"RETURN_GRACE" usage:"How long after the expiration date the order can be returned to the courier"`
+
+	PickUpPoints map[int64]PolicyOverrideConfig `yaml:"pick_up_points" usage:"Policies of pick-up points by ID (config file only)"`
+}
+
+// PolicyOverrideConfig holds rules of a pick-up point, rules that are not set are taken from the default policy.
+// The page size is not overridden, the refund list is shared by all pick-up points
+type PolicyOverrideConfig struct {
+	RefundWindow *time.Duration `yaml:"refund_window,omitempty"`
+	MaxStorage   *time.Duration `yaml:"max_storage,omitempty"`
+	PickUpGrace  *time.Duration `yaml:"pick_up_grace,omitempty"`
+	ReturnGrace  *time.Duration `yaml:"return_grace,omitempty"`
+}
+
+// NewPolicyConfig returns rules that used to be hardcoded
+func NewPolicyConfig() *PolicyConfig {
+	return &PolicyConfig{
+		RefundWindow: 48 * time.Hour,
+		PageSize:     10,
+	}
+}
+
+// Validate checks the default policy and the policy of every pick-up point
+func (policyConfig *PolicyConfig) Validate() error {
+	policies := policyConfig.Policies()
+	if err := validatePolicy(policies.Default); err != nil {
+		return err
+	}
+
+	ids := make([]int64, 0, len(policies.PickUpPoints))
+	for id := range policies.PickUpPoints {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		if id <= 0 {
+			return fmt.Errorf("pick-up point ID %d must be positive", id)
+		}
+		if err := validatePolicy(policies.PickUpPoints[id]); err != nil {
+			return fmt.Errorf("pick-up point %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func validatePolicy(policy model.Policy) error {
+	if policy.RefundWindow <= 0 {
+		return fmt.Errorf("refund window must be positive")
+	}
+	if policy.PageSize <= 0 {
+		return fmt.Errorf("page size must be positive")
+	}
+	if policy.MaxStorage < 0 || policy.PickUpGrace < 0 || policy.ReturnGrace < 0 {
+		return fmt.Errorf("storage duration and grace periods must not be negative")
+	}
+	if policy.ReturnGrace < policy.PickUpGrace {
+		return fmt.Errorf("return grace must not be shorter than pick-up grace, orders would be returned while clients can receive them")
+	}
+	return nil
+}
+
+// Policies returns the default policy and policies of pick-up points with overrides applied
+func (policyConfig *PolicyConfig) Policies() model.Policies {
+	policies := model.Policies{
+		Default: model.Policy{
+			RefundWindow: policyConfig.RefundWindow,
+			PageSize:     policyConfig.PageSize,
+			MaxStorage:   policyConfig.MaxStorage,
+			PickUpGrace:  policyConfig.PickUpGrace,
+			ReturnGrace:  policyConfig.ReturnGrace,
+		},
+		PickUpPoints: make(map[int64]model.Policy, len(policyConfig.PickUpPoints)),
+	}
+	for id, override := range policyConfig.PickUpPoints {
+		policy := policies.Default
+		if override.RefundWindow != nil {
+			policy.RefundWindow = *override.RefundWindow
+		}
+		if override.MaxStorage != nil {
+			policy.MaxStorage = *override.MaxStorage
+		}
+		if override.PickUpGrace != nil {
+			policy.PickUpGrace = *override.PickUpGrace
+		}
+		if override.ReturnGrace != nil {
+			policy.ReturnGrace = *override.ReturnGrace
+		}
+		policies.PickUpPoints[id] = policy
+	}
+	return policies
+}
@@ -0,0 +1,68 @@
+package configuration
+
+import (
+	"GOHW-1/internal/model"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPolicyConfig_Policies(t *testing.T) {
+	t.Parallel()
+	t.Run("overrides test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		fileName := writeConfigFile(t, "runtime:\n  policy:\n    max_storage: 336h\n    pick_up_points:\n"+
+			"      7:\n        refund_window: 24h\n        pick_up_grace: 12h\n        return_grace: 24h\n")
+		config := NewConfig()
+		require.NoError(t, config.SetFile(fileName))
+
+		// act
+		policies := config.Runtime.Policy.Policies()
+
+		// assert
+		require.NoError(t, config.Validate())
+		assert.Equal(t, model.Policy{RefundWindow: 48 * time.Hour, PageSize: 10, MaxStorage: 336 * time.Hour}, policies.For(1))
+		assert.Equal(t, model.Policy{RefundWindow: 24 * time.Hour, PageSize: 10, MaxStorage: 336 * time.Hour,
+			PickUpGrace: 12 * time.Hour, ReturnGrace: 24 * time.Hour}, policies.For(7))
+	})
+	t.Run("invalid override test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		fileName := writeConfigFile(t, "runtime:\n  policy:\n    pick_up_points:\n      3:\n        pick_up_grace: 1h\n")
+		config := NewConfig()
+		require.NoError(t, config.SetFile(fileName))
+
+		// act
+		err := config.Validate()
+
+		// assert
+		require.Error(t, err)
+		assert.Equal(t, "invalid runtime configuration: pick-up point 3: return grace must not be shorter than pick-up grace, "+
+			"orders would be returned while clients can receive them", err.Error())
+	})
+	t.Run("show test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		refundWindow := 24 * time.Hour
+		config := NewConfig()
+		config.Runtime.Policy.PickUpPoints = map[int64]PolicyOverrideConfig{7: {RefundWindow: &refundWindow}}
+		var buffer bytes.Buffer
+		require.NoError(t, config.Show(&buffer))
+		loaded := NewConfig()
+
+		// act
+		err := loaded.SetFile(writeConfigFile(t, buffer.String()))
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, config.Runtime.Policy.Policies(), loaded.Runtime.Policy.Policies())
+		for _, s := range settingsOf(config) {
+			if s.key == "runtime.policy.pick_up_points" {
+				assert.Equal(t, "{7: {refund_window: 24h0m0s}}", s.String())
+			}
+		}
+	})
+}
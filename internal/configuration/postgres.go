@@ -0,0 +1,64 @@
+package configuration
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// PoolConfig tunes the connection pool, zero sizes and durations keep pgx defaults
+type PoolConfig struct {
+	MaxConns            int           `yaml:"max_conns" env:"POSTGRES_MAX_CONNS" usage:"Maximum connections in the pool"`
+	MinConns            int           `yaml:"min_conns" env:"POSTGRES_MIN_CONNS" usage:"Connections kept open when idle"`
+	MaxConnLifetime     time.Duration `yaml:"max_conn_lifetime" env:"POSTGRES_MAX_CONN_LIFETIME" usage:"Connections are closed after this time"`
+	MaxConnIdleTime     time.Duration `yaml:"max_conn_idle_time" env:"POSTGRES_MAX_CONN_IDLE_TIME" usage:"Idle connections are closed after this time"`
+	StatementTimeout    time.Duration `yaml:"statement_timeout" env:"POSTGRES_STATEMENT_TIMEOUT" usage:"Queries running longer are cancelled by the server (0 - no timeout)"`
+	ConnectAttempts     int           `yaml:"connect_attempts" env:"POSTGRES_CONNECT_ATTEMPTS" usage:"Attempts to connect at startup"`
+	ConnectBackoff      time.Duration `yaml:"connect_backoff" env:"POSTGRES_CONNECT_BACKOFF" usage:"Delay after the first failed attempt, doubled after each next one"`
+	HealthCheckInterval time.Duration `yaml:"health_check_interval" env:"POSTGRES_HEALTH_CHECK_INTERVAL" usage:"How often the database is pinged for the readiness endpoint (0 - never)"`
+}
+
+func NewPoolConfig() *PoolConfig {
+	return &PoolConfig{
+		MaxConns:            10,
+		MaxConnLifetime:     time.Hour,
+		MaxConnIdleTime:     30 * time.Minute,
+		ConnectAttempts:     5,
+		ConnectBackoff:      500 * time.Millisecond,
+		HealthCheckInterval: 10 * time.Second,
+	}
+}
+
+// Validate checks that pool limits and retries are consistent
+func (poolConfig *PoolConfig) Validate() error {
+	if poolConfig.MaxConns < 0 || poolConfig.MinConns < 0 {
+		return fmt.Errorf("pool sizes must not be negative")
+	}
+	if poolConfig.MaxConns > 0 && poolConfig.MinConns > poolConfig.MaxConns {
+		return fmt.Errorf("pool min_conns %d is greater than max_conns %d", poolConfig.MinConns, poolConfig.MaxConns)
+	}
+	if poolConfig.MaxConnLifetime < 0 || poolConfig.MaxConnIdleTime < 0 || poolConfig.StatementTimeout < 0 ||
+		poolConfig.ConnectBackoff < 0 || poolConfig.HealthCheckInterval < 0 {
+		return fmt.Errorf("pool durations must not be negative")
+	}
+	if poolConfig.ConnectAttempts < 1 {
+		return fmt.Errorf("connect attempts must be at least 1")
+	}
+	return nil
+}
+
+// validateSSL checks the sslmode and that the client certificate comes with its key
+func (dbCredentials *DBCredentials) validateSSL() error {
+	if !slices.Contains(sslModes, dbCredentials.SSLMode) {
+		return fmt.Errorf("invalid sslmode %q: expected disable, allow, prefer, require, verify-ca or verify-full", dbCredentials.SSLMode)
+	}
+	if (dbCredentials.SSLCert == "") != (dbCredentials.SSLKey == "") {
+		return fmt.Errorf("sslcert and sslkey must be given together")
+	}
+	if dbCredentials.SSLRootCert == "" && (dbCredentials.SSLMode == "verify-ca" || dbCredentials.SSLMode == "verify-full") {
+		return fmt.Errorf("sslrootcert is required by sslmode %s", dbCredentials.SSLMode)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package configuration
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDBCredentials_Validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		modify  func(dbCredentials *DBCredentials)
+		wantErr string
+	}{
+		{
+			name:   "defaults test",
+			modify: func(dbCredentials *DBCredentials) {},
+		},
+		{
+			name:    "sslmode test",
+			modify:  func(dbCredentials *DBCredentials) { dbCredentials.SSLMode = "on" },
+			wantErr: "invalid sslmode \"on\": expected disable, allow, prefer, require, verify-ca or verify-full",
+		},
+		{
+			name:    "verify without root cert test",
+			modify:  func(dbCredentials *DBCredentials) { dbCredentials.SSLMode = "verify-full" },
+			wantErr: "sslrootcert is required by sslmode verify-full",
+		},
+		{
+			name:    "cert without key test",
+			modify:  func(dbCredentials *DBCredentials) { dbCredentials.SSLCert = "client.crt" },
+			wantErr: "sslcert and sslkey must be given together",
+		},
+		{
+			name:    "pool sizes test",
+			modify:  func(dbCredentials *DBCredentials) { dbCredentials.Pool.MinConns = 11 },
+			wantErr: "pool min_conns 11 is greater than max_conns 10",
+		},
+		{
+			name:    "connect attempts test",
+			modify:  func(dbCredentials *DBCredentials) { dbCredentials.Pool.ConnectAttempts = 0 },
+			wantErr: "connect attempts must be at least 1",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// arrange
+			dbCredentials := NewConfig().Postgres
+			tt.modify(&dbCredentials)
+
+			// act
+			err := dbCredentials.Validate()
+
+			// assert
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package configuration
+
+import (
+	"GOHW-1/internal/model"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// RuntimeConfig holds settings that are reloaded without restarting the application
+type RuntimeConfig struct {
+	LogLevel  string          `yaml:"log_level" env:"LOG_LEVEL" usage:"debug, info, warn or error"`
+	Policy    PolicyConfig    `yaml:"policy"`
+	Packaging PackagingConfig `yaml:"packaging"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+}
+
+type PackagingConfig struct {
+	Package PackagingRuleConfig `yaml:"package"`
+	Carton  PackagingRuleConfig `yaml:"carton"`
+	Film    PackagingRuleConfig `yaml:"film"`
+}
+
+// PackagingRuleConfig limits the order weight (0 - no limit) and adds the cost of packaging to its price
+type PackagingRuleConfig struct {
+	MaxWeight float64 `yaml:"max_weight" usage:"Maximum order weight (0 - no limit)"`
+	ExtraCost float64 `yaml:"extra_cost" usage:"Cost added to the order price"`
+}
+
+// RateLimitConfig limits requests of every client (user or IP address) to a route of the http API
+// (0 requests per second - no limit). Routes override the limit for single routes
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second" env:"RATE_LIMIT_RPS" usage:"Requests per second of a client to a route (0 - no limit)"`
+	Burst             int     `yaml:"burst" env:"RATE_LIMIT_BURST" usage:"Requests allowed at once above the rate"`
+
+	Routes map[string]RouteRateLimitConfig `yaml:"routes" usage:"Limits by route, e.g. \"POST /pick-up-point\" (config file only)"`
+}
+
+// RouteRateLimitConfig is the limit of a route (0 requests per second - no limit)
+type RouteRateLimitConfig struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	Burst             int     `yaml:"burst"`
+}
+
+// For returns the limit of the route template requested with the method. A route key with the method
+// ("PUT /pick-up-point/{key}") takes precedence over a key of the template alone, which applies to all methods
+func (rateLimitConfig *RateLimitConfig) For(method string, route string) RouteRateLimitConfig {
+	if limit, ok := rateLimitConfig.Routes[method+" "+route]; ok {
+		return limit
+	}
+	if limit, ok := rateLimitConfig.Routes[route]; ok {
+		return limit
+	}
+	return RouteRateLimitConfig{RequestsPerSecond: rateLimitConfig.RequestsPerSecond, Burst: rateLimitConfig.Burst}
+}
+
+// Validate checks the default limit and the limit of every route
+func (rateLimitConfig *RateLimitConfig) Validate() error {
+	if err := validateRateLimit(rateLimitConfig.RequestsPerSecond, rateLimitConfig.Burst); err != nil {
+		return err
+	}
+	routes := make([]string, 0, len(rateLimitConfig.Routes))
+	for route := range rateLimitConfig.Routes {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	for _, route := range routes {
+		path := route
+		if method, template, ok := strings.Cut(route, " "); ok && method == strings.ToUpper(method) {
+			path = template
+		}
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("rate limit route %q must be a path template with an optional method, e.g. \"POST /pick-up-point\"", route)
+		}
+		limit := rateLimitConfig.Routes[route]
+		if err := validateRateLimit(limit.RequestsPerSecond, limit.Burst); err != nil {
+			return fmt.Errorf("route %q: %w", route, err)
+		}
+	}
+	return nil
+}
+
+func validateRateLimit(requestsPerSecond float64, burst int) error {
+	if requestsPerSecond < 0 {
+		return fmt.Errorf("rate limit must not be negative")
+	}
+	if requestsPerSecond > 0 && burst <= 0 {
+		return fmt.Errorf("rate limit burst must be positive")
+	}
+	return nil
+}
+
+// NewRuntimeConfig returns settings that used to be hardcoded
+func NewRuntimeConfig() *RuntimeConfig {
+	rules := model.GetPackagingRules()
+	return &RuntimeConfig{
+		LogLevel: "info",
+		Policy:   *NewPolicyConfig(),
+		Packaging: PackagingConfig{
+			Package: PackagingRuleConfig(rules[model.Package]),
+			Carton:  PackagingRuleConfig(rules[model.Carton]),
+			Film:    PackagingRuleConfig(rules[model.Film]),
+		},
+		RateLimit: RateLimitConfig{Burst: 20},
+	}
+}
+
+// Validate checks that the settings can be applied
+func (runtimeConfig *RuntimeConfig) Validate() error {
+	if _, err := runtimeConfig.Level(); err != nil {
+		return err
+	}
+	if err := runtimeConfig.Policy.Validate(); err != nil {
+		return err
+	}
+	for name, rule := range runtimeConfig.PackagingRules() {
+		if rule.MaxWeight < 0 || rule.ExtraCost < 0 {
+			return fmt.Errorf("%s packaging rule must not be negative", name)
+		}
+	}
+	return runtimeConfig.RateLimit.Validate()
+}
+
+// Level returns the slog level of LogLevel
+func (runtimeConfig *RuntimeConfig) Level() (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(runtimeConfig.LogLevel))); err != nil {
+		return 0, fmt.Errorf("invalid log level %q: expected debug, info, warn or error", runtimeConfig.LogLevel)
+	}
+	return level, nil
+}
+
+// PackagingRules returns packaging rules by packaging type
+func (runtimeConfig *RuntimeConfig) PackagingRules() map[string]model.PackagingRule {
+	return map[string]model.PackagingRule{
+		model.Package: model.PackagingRule(runtimeConfig.Packaging.Package),
+		model.Carton:  model.PackagingRule(runtimeConfig.Packaging.Carton),
+		model.Film:    model.PackagingRule(runtimeConfig.Packaging.Film),
+	}
+}
+
+// Runtime shares reloadable settings between the watcher and their readers.
+// Settings are swapped as a whole, so readers never see a partially applied reload
+type Runtime struct {
+	current  atomic.Pointer[RuntimeConfig]
+	logLevel slog.LevelVar
+}
+
+func NewRuntime(runtimeConfig RuntimeConfig) *Runtime {
+	runtime := &Runtime{}
+	runtime.Store(runtimeConfig)
+	return runtime
+}
+
+// Get returns current settings, they must not be modified
+func (runtime *Runtime) Get() *RuntimeConfig {
+	return runtime.current.Load()
+}
+
+// Store replaces current settings, they must be valid
+func (runtime *Runtime) Store(runtimeConfig RuntimeConfig) {
+	level, err := runtimeConfig.Level()
+	if err == nil {
+		runtime.logLevel.Set(level)
+	}
+	runtime.current.Store(&runtimeConfig)
+}
+
+// Policies returns business rules of current settings
+func (runtime *Runtime) Policies() model.Policies {
+	return runtime.Get().Policy.Policies()
+}
+
+// LogLevel returns the level that follows LogLevel of current settings, for use in slog handlers
+func (runtime *Runtime) LogLevel() slog.Leveler {
+	return &runtime.logLevel
+}
@@ -0,0 +1,68 @@
+package configuration
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRateLimitConfig_For(t *testing.T) {
+	t.Parallel()
+	rateLimitConfig := RateLimitConfig{RequestsPerSecond: 10, Burst: 20, Routes: map[string]RouteRateLimitConfig{
+		"POST /pick-up-point":  {RequestsPerSecond: 1, Burst: 2},
+		"/pick-up-point/{key}": {RequestsPerSecond: 5, Burst: 5},
+	}}
+	tests := []struct {
+		name   string
+		method string
+		route  string
+		want   RouteRateLimitConfig
+	}{
+		{name: "method test", method: "POST", route: "/pick-up-point", want: RouteRateLimitConfig{RequestsPerSecond: 1, Burst: 2}},
+		{name: "all methods test", method: "PUT", route: "/pick-up-point/{key}", want: RouteRateLimitConfig{RequestsPerSecond: 5, Burst: 5}},
+		{name: "default test", method: "GET", route: "/pick-up-point", want: RouteRateLimitConfig{RequestsPerSecond: 10, Burst: 20}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// act
+			got := rateLimitConfig.For(tt.method, tt.route)
+
+			// assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRateLimitConfig_Validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		routes map[string]RouteRateLimitConfig
+		want   string
+	}{
+		{name: "valid test", routes: map[string]RouteRateLimitConfig{"GET /pick-up-point": {RequestsPerSecond: 1, Burst: 1}}},
+		{name: "no path test", routes: map[string]RouteRateLimitConfig{"GET": {}},
+			want: `rate limit route "GET" must be a path template with an optional method, e.g. "POST /pick-up-point"`},
+		{name: "no burst test", routes: map[string]RouteRateLimitConfig{"/pick-up-point": {RequestsPerSecond: 1}},
+			want: `route "/pick-up-point": rate limit burst must be positive`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// arrange
+			rateLimitConfig := RateLimitConfig{Routes: tt.routes}
+
+			// act
+			err := rateLimitConfig.Validate()
+
+			// assert
+			if tt.want == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package configuration
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	SenderKafka  = "kafka"
+	SenderFile   = "file"
+	SenderStdout = "stdout"
+	SenderMemory = "memory"
+	SenderNone   = "none"
+)
+
+type SenderConfig struct {
+	Type     string `yaml:"sink" env:"EVENTS_SINK" usage:"kafka, file (JSON lines), stdout, memory or none"`
+	Fallback string `yaml:"fallback" env:"EVENTS_FALLBACK" usage:"Sink used when kafka is unavailable: file, stdout, memory or none"`
+	FilePath string `yaml:"file" env:"EVENTS_FILE" usage:"File of the file sink"`
+}
+
+// NewSenderConfig returns event sink settings that send to kafka and fall back to a local file
+func NewSenderConfig() *SenderConfig {
+	return &SenderConfig{
+		Type:     SenderKafka,
+		Fallback: SenderFile,
+		FilePath: "events.jsonl",
+	}
+}
+
+// SetEnv applies settings from EVENTS_* environment variables
+func (senderConfig *SenderConfig) SetEnv() error {
+	return applyEnv(senderConfig, os.LookupEnv)
+}
+
+// Validate checks that the event sink and its fallback are known
+func (senderConfig *SenderConfig) Validate() error {
+	if !oneOf(senderConfig.Type, SenderKafka, SenderFile, SenderStdout, SenderMemory, SenderNone) {
+		return fmt.Errorf("invalid events sink %q: expected kafka, file, stdout, memory or none", senderConfig.Type)
+	}
+	if !oneOf(senderConfig.Fallback, SenderFile, SenderStdout, SenderMemory, SenderNone) {
+		return fmt.Errorf("invalid events fallback %q: expected file, stdout, memory or none", senderConfig.Fallback)
+	}
+	if (senderConfig.Type == SenderFile || senderConfig.Fallback == SenderFile) && senderConfig.FilePath == "" {
+		return fmt.Errorf("events file is not given")
+	}
+	return nil
+}
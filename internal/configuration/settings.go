@@ -0,0 +1,249 @@
+package configuration
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	configFileEnv = "CONFIG_FILE"
+	nowUsage      = "Run the command as if it were this RFC 3339 time, e.g. to reproduce an incident"
+)
+
+// Config holds all settings of the application. They are read in layers, each one overriding the previous:
+// defaults, the YAML file, environment variables and global command line flags
+type Config struct {
+	HTTP     HTTPConfig    `yaml:"http"`
+	GRPC     GRPCConfig    `yaml:"grpc"`
+	Postgres DBCredentials `yaml:"postgres"`
+	Kafka    KafkaConfig   `yaml:"kafka"`
+	Events   SenderConfig  `yaml:"events"`
+	Log      LogConfig     `yaml:"log"`
+	Tracing  TracingConfig `yaml:"tracing"`
+	Health   HealthConfig  `yaml:"health"`
+	Runtime  RuntimeConfig `yaml:"runtime"`
+
+	// File is the YAML file the settings were read from, if any
+	File string `yaml:"-"`
+	// Now is the time commands run at, given with -now to reproduce incidents. Zero - the system clock
+	Now time.Time `yaml:"-"`
+}
+
+// NewConfig returns default settings matching docker-compose.yml and the Makefile
+func NewConfig() *Config {
+	return &Config{
+		HTTP:     *NewHTTPConfig(),
+		GRPC:     *NewGRPCConfig(),
+		Postgres: DBCredentials{Host: "localhost", Port: "5432", User: "postgres", DBname: "gohw", SSLMode: "disable", Pool: *NewPoolConfig()},
+		Kafka:    *NewKafkaConfig(),
+		Events:   *NewSenderConfig(),
+		Log:      *NewLogConfig(),
+		Tracing:  *NewTracingConfig(),
+		Health:   *NewHealthConfig(),
+		Runtime:  *NewRuntimeConfig(),
+	}
+}
+
+// Load reads settings in layers and validates them. Global flags (-config and one flag per setting,
+// e.g. -kafka.topic=logs) are taken from the beginning of args, the rest (the command) is returned
+func Load(args []string) (*Config, []string, error) {
+	config := NewConfig()
+	configFile := os.Getenv(configFileEnv)
+
+	type override struct{ key, value string }
+	var overrides []override
+	flagSet := flag.NewFlagSet("global", flag.ContinueOnError)
+	flagSet.SetOutput(io.Discard)
+	flagSet.StringVar(&configFile, "config", configFile, "YAML config file")
+	flagSet.Func("now", nowUsage, func(value string) error {
+		now, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("must be an RFC 3339 time (e.g. 2024-04-01T12:00:00Z)")
+		}
+		config.Now = now
+		return nil
+	})
+	for _, s := range settingsOf(config) {
+		if s.fileOnly() {
+			continue
+		}
+		key := s.key
+		flagSet.Func(key, s.usage, func(value string) error {
+			overrides = append(overrides, override{key: key, value: value})
+			return nil
+		})
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if configFile != "" {
+		if err := config.SetFile(configFile); err != nil {
+			return nil, nil, err
+		}
+		config.File = configFile
+	}
+	if fileName := os.Getenv(kafkaConfigFileEnv); fileName != "" {
+		if err := config.Kafka.SetFile(fileName); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := config.SetEnv(); err != nil {
+		return nil, nil, err
+	}
+	for _, o := range overrides {
+		if err := config.Set(o.key, o.value); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return config, flagSet.Args(), nil
+}
+
+// PrintFlags writes global flags accepted by Load
+func PrintFlags(w io.Writer) {
+	fmt.Fprintf(w, "  -config string\n    \tYAML config file (default $%s)\n", configFileEnv)
+	fmt.Fprintf(w, "  -now value\n    \t%s\n", nowUsage)
+	for _, s := range settingsOf(NewConfig()) {
+		if s.fileOnly() {
+			continue
+		}
+		fmt.Fprintf(w, "  -%s value\n    \t%s", s.key, s.usage)
+		if s.env != "" {
+			fmt.Fprintf(w, " ($%s)", s.env)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// SetFile applies settings from a YAML file. Unknown keys are rejected to catch typos
+func (config *Config) SetFile(fileName string) error {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return fmt.Errorf("unable to read config file: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config file %s: %w", fileName, err)
+	}
+	return nil
+}
+
+// SetEnv applies settings from environment variables
+func (config *Config) SetEnv() error {
+	return applyEnv(config, os.LookupEnv)
+}
+
+// Set applies one setting by its key (e.g. "kafka.topic")
+func (config *Config) Set(key string, value string) error {
+	for _, s := range settingsOf(config) {
+		if s.key == key {
+			return s.set("-"+key, value)
+		}
+	}
+	return fmt.Errorf("unknown setting %q", key)
+}
+
+// Validate checks every section, errors name the section
+func (config *Config) Validate() error {
+	if err := config.HTTP.Validate(); err != nil {
+		return fmt.Errorf("invalid http configuration: %w", err)
+	}
+	if err := config.GRPC.Validate(); err != nil {
+		return fmt.Errorf("invalid grpc configuration: %w", err)
+	}
+	if err := config.Postgres.Validate(); err != nil {
+		return fmt.Errorf("invalid postgres configuration: %w", err)
+	}
+	if err := config.Kafka.Validate(); err != nil {
+		return fmt.Errorf("invalid kafka configuration: %w", err)
+	}
+	if err := config.Events.Validate(); err != nil {
+		return fmt.Errorf("invalid events configuration: %w", err)
+	}
+	if err := config.Log.Validate(); err != nil {
+		return fmt.Errorf("invalid log configuration: %w", err)
+	}
+	if err := config.Tracing.Validate(); err != nil {
+		return fmt.Errorf("invalid tracing configuration: %w", err)
+	}
+	if err := config.Health.Validate(); err != nil {
+		return fmt.Errorf("invalid health configuration: %w", err)
+	}
+	if err := config.Runtime.Validate(); err != nil {
+		return fmt.Errorf("invalid runtime configuration: %w", err)
+	}
+	return nil
+}
+
+// Show writes settings as YAML with secrets redacted
+func (config *Config) Show(w io.Writer) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, s := range settingsOf(config) {
+		parts := strings.Split(s.key, ".")
+		node := root
+		for _, part := range parts[:len(parts)-1] {
+			node = mappingChild(node, part)
+		}
+		node.Content = append(node.Content, scalarNode(parts[len(parts)-1], "!!str"), valueNode(s))
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// mappingChild returns the mapping stored under the key, creating it if needed
+func mappingChild(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode}
+	node.Content = append(node.Content, scalarNode(key, "!!str"), child)
+	return child
+}
+
+func valueNode(s setting) *yaml.Node {
+	if s.fileOnly() {
+		node := &yaml.Node{}
+		if s.value.Len() == 0 || node.Encode(s.value.Interface()) != nil {
+			return &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}
+		}
+		return node
+	}
+	if s.value.Kind() == reflect.Slice {
+		sequence := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for i := 0; i < s.value.Len(); i++ {
+			sequence.Content = append(sequence.Content, scalarNode(s.value.Index(i).String(), "!!str"))
+		}
+		return sequence
+	}
+	switch {
+	case s.secret && !s.value.IsZero(), s.value.Type() == durationType, s.value.Kind() == reflect.String:
+		return scalarNode(s.shown(), "!!str")
+	default:
+		// numbers and booleans are plain scalars, an explicit tag would be printed for floats without a fraction
+		return scalarNode(s.shown(), "")
+	}
+}
+
+func scalarNode(value string, tag string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
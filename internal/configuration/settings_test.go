@@ -0,0 +1,153 @@
+package configuration
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	fileName := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(fileName, []byte(content), 0600))
+	return fileName
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("layers test", func(t *testing.T) {
+		// arrange
+		fileName := writeConfigFile(t, "kafka:\n  topic: from-file\n  acks: leader\n  flush_frequency: 250ms\n"+
+			"  brokers: [kafka1:9092, kafka2:9092]\nhttp:\n  secure_port: \":9443\"\n")
+		t.Setenv("KAFKA_TOPIC", "from-env")
+		t.Setenv("KAFKA_ACKS", "none")
+
+		// act
+		config, args, err := Load([]string{"-config=" + fileName, "-kafka.acks=all", "cl-orders", "-cid=1"})
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"cl-orders", "-cid=1"}, args)
+		assert.Equal(t, "from-env", config.Kafka.Topic)
+		assert.Equal(t, "all", config.Kafka.Acks)
+		assert.Equal(t, 250*time.Millisecond, config.Kafka.FlushFrequency)
+		assert.Equal(t, []string{"kafka1:9092", "kafka2:9092"}, config.Kafka.Brokers)
+		assert.Equal(t, ":9443", config.HTTP.SecurePort)
+		assert.Equal(t, ":9000", config.HTTP.InsecurePort)
+	})
+	t.Run("unknown key in file test", func(t *testing.T) {
+		// arrange
+		fileName := writeConfigFile(t, "kafka:\n  topik: logs\n")
+
+		// act
+		_, _, err := Load([]string{"-config=" + fileName, "help"})
+
+		// assert
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "field topik not found")
+	})
+	t.Run("invalid flag value test", func(t *testing.T) {
+		// act
+		_, _, err := Load([]string{"-kafka.batch_size=many", "help"})
+
+		// assert
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "-kafka.batch_size must be an integer")
+	})
+	t.Run("now flag test", func(t *testing.T) {
+		// act
+		config, args, err := Load([]string{"-now=2024-04-01T12:00:00+03:00", "refund-list"})
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"refund-list"}, args)
+		assert.True(t, config.Now.Equal(time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)))
+	})
+	t.Run("invalid section test", func(t *testing.T) {
+		// arrange
+		t.Setenv("EVENTS_SINK", "printer")
+
+		// act
+		_, _, err := Load([]string{"help"})
+
+		// assert
+		require.Error(t, err)
+		assert.Equal(t, "invalid events configuration: invalid events sink \"printer\": expected kafka, file, stdout, memory or none", err.Error())
+	})
+	t.Run("critical dependencies test", func(t *testing.T) {
+		// arrange
+		t.Setenv("HEALTH_CRITICAL", "postgres, kafka_consumer")
+
+		// act
+		config, _, err := Load([]string{"help"})
+
+		// assert
+		require.NoError(t, err)
+		assert.True(t, config.Health.IsCritical(DependencyKafkaConsumer))
+		assert.False(t, config.Health.IsCritical(DependencyStorage))
+	})
+	t.Run("grpc address test", func(t *testing.T) {
+		// arrange
+		t.Setenv("GRPC_ADDRESS", "9002")
+
+		// act
+		_, _, err := Load([]string{"help"})
+
+		// assert
+		assert.EqualError(t, err, "invalid grpc configuration: invalid address \"9002\": address 9002: missing port in address")
+	})
+	t.Run("unknown critical dependency test", func(t *testing.T) {
+		// arrange
+		t.Setenv("HEALTH_CRITICAL", "redis")
+
+		// act
+		_, _, err := Load([]string{"help"})
+
+		// assert
+		assert.EqualError(t, err, "invalid health configuration: unknown critical dependency \"redis\": "+
+			"expected postgres, kafka_producer, kafka_consumer or storage")
+	})
+}
+
+func TestConfig_Show(t *testing.T) {
+	t.Parallel()
+	t.Run("secrets test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		config := NewConfig()
+		config.Kafka.SASL.Password = "kafka-secret"
+		var buffer bytes.Buffer
+
+		// act
+		err := config.Show(&buffer)
+
+		// assert
+		require.NoError(t, err)
+		assert.NotContains(t, buffer.String(), "kafka-secret")
+		assert.NotContains(t, buffer.String(), "erbaev")
+		assert.Contains(t, buffer.String(), "    password: <redacted>")
+		assert.Contains(t, buffer.String(), "  buffer_retry_interval: 5s")
+	})
+	t.Run("round trip test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		config := NewConfig()
+		config.Kafka.BatchSize = 100
+		config.Postgres.Port = "5433"
+		var buffer bytes.Buffer
+		require.NoError(t, config.Show(&buffer))
+		fileName := writeConfigFile(t, buffer.String())
+		loaded := NewConfig()
+
+		// act
+		err := loaded.SetFile(fileName)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, 100, loaded.Kafka.BatchSize)
+		assert.Equal(t, "5433", loaded.Postgres.Port)
+		assert.Equal(t, config.Kafka.Brokers, loaded.Kafka.Brokers)
+	})
+}
@@ -0,0 +1,50 @@
+package configuration
+
+import (
+	"fmt"
+	"net/url"
+)
+
+const (
+	TracingNone   = "none"
+	TracingOTLP   = "otlp"
+	TracingStdout = "stdout"
+)
+
+// TracingConfig chooses where trace spans are exported
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" usage:"none, otlp (OTLP/HTTP collector) or stdout"`
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT" usage:"URL of the OTLP/HTTP collector"`
+	ServiceName string  `yaml:"service_name" env:"TRACING_SERVICE_NAME" usage:"service.name of exported spans"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" usage:"Share of new traces that are sampled, from 0 to 1"`
+}
+
+// NewTracingConfig returns tracing settings that export nothing, the endpoint is a collector of docker-compose.yml
+func NewTracingConfig() *TracingConfig {
+	return &TracingConfig{
+		Exporter:    TracingNone,
+		Endpoint:    "http://localhost:4318",
+		ServiceName: "gohw",
+		SampleRatio: 1,
+	}
+}
+
+// Validate checks the exporter, the collector URL and the sample ratio
+func (tracingConfig *TracingConfig) Validate() error {
+	if !oneOf(tracingConfig.Exporter, TracingNone, TracingOTLP, TracingStdout) {
+		return fmt.Errorf("invalid exporter %q: expected none, otlp or stdout", tracingConfig.Exporter)
+	}
+	if tracingConfig.Exporter == TracingOTLP {
+		endpoint, err := url.Parse(tracingConfig.Endpoint)
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			return fmt.Errorf("invalid endpoint %q: expected an http or https URL", tracingConfig.Endpoint)
+		}
+	}
+	if tracingConfig.ServiceName == "" {
+		return fmt.Errorf("service name is not given")
+	}
+	if tracingConfig.SampleRatio < 0 || tracingConfig.SampleRatio > 1 {
+		return fmt.Errorf("sample ratio must be from 0 to 1, got %v", tracingConfig.SampleRatio)
+	}
+	return nil
+}
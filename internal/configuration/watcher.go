@@ -0,0 +1,98 @@
+package configuration
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const runtimeSection = "runtime."
+
+// Watcher reloads the configuration on SIGHUP or when the config file changes and applies runtime settings.
+// A configuration that fails to load or validate is rejected and the previous settings are kept.
+// Other sections are only reported, they take effect after a restart
+type Watcher struct {
+	args    []string
+	current *Config
+	runtime *Runtime
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher returns a watcher of the configuration loaded from args with Load
+func NewWatcher(args []string, config *Config, runtime *Runtime) *Watcher {
+	watcher := &Watcher{args: args, current: config, runtime: runtime}
+	watcher.modTime, watcher.size = watcher.stat()
+	return watcher
+}
+
+// Run checks the config file every interval and reloads it on change or SIGHUP until the context is cancelled
+func (watcher *Watcher) Run(ctx context.Context, interval time.Duration) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sighup:
+			slog.Info("configuration reload: SIGHUP received")
+			watcher.Reload()
+		case <-ticker.C:
+			if modTime, size := watcher.stat(); !modTime.Equal(watcher.modTime) || size != watcher.size {
+				watcher.modTime, watcher.size = modTime, size
+				slog.Info("configuration reload: file changed", "file", watcher.current.File)
+				watcher.Reload()
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Reload loads the configuration again and swaps runtime settings if it is valid
+func (watcher *Watcher) Reload() error {
+	config, _, err := Load(watcher.args)
+	if err != nil {
+		slog.Warn("configuration reload rejected, keeping the previous settings", "error", err)
+		return err
+	}
+
+	oldSettings := settingsOf(watcher.current)
+	changed := 0
+	for i, s := range settingsOf(config) {
+		oldValue, newValue := oldSettings[i].shown(), s.shown()
+		if oldValue == newValue {
+			continue
+		}
+		if strings.HasPrefix(s.key, runtimeSection) {
+			slog.Info("configuration reload: setting changed", "setting", s.key, "old", oldValue, "new", newValue)
+			changed++
+		} else {
+			slog.Warn("configuration reload: setting changed, restart to apply", "setting", s.key, "old", oldValue, "new", newValue)
+		}
+	}
+
+	watcher.current.Runtime = config.Runtime
+	watcher.runtime.Store(config.Runtime)
+	if changed == 0 {
+		slog.Info("configuration reload: runtime settings are unchanged")
+	}
+	return nil
+}
+
+func (watcher *Watcher) stat() (time.Time, int64) {
+	if watcher.current.File == "" {
+		return time.Time{}, 0
+	}
+	info, err := os.Stat(watcher.current.File)
+	if err != nil {
+		return time.Time{}, 0
+	}
+	return info.ModTime(), info.Size()
+}
@@ -0,0 +1,106 @@
+package configuration
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWatcher_Reload(t *testing.T) {
+	t.Parallel()
+	t.Run("smoke test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		fileName := writeConfigFile(t, "runtime:\n  policy:\n    refund_window: 48h\n")
+		args := []string{"-config=" + fileName, "http"}
+		config, _, err := Load(args)
+		require.NoError(t, err)
+		runtime := NewRuntime(config.Runtime)
+		watcher := NewWatcher(args, config, runtime)
+		require.NoError(t, os.WriteFile(fileName, []byte("runtime:\n  policy:\n    refund_window: 72h\n  log_level: warn\n"+
+			"  packaging:\n    carton:\n      max_weight: 50\n"), 0600))
+
+		// act
+		err = watcher.Reload()
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, 72*time.Hour, runtime.Policies().Default.RefundWindow)
+		assert.Equal(t, "warn", runtime.Get().LogLevel)
+		assert.Equal(t, 50.0, runtime.Get().PackagingRules()["carton"].MaxWeight)
+		assert.Equal(t, 20.0, runtime.Get().PackagingRules()["carton"].ExtraCost)
+	})
+	t.Run("invalid config test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		fileName := writeConfigFile(t, "runtime:\n  policy:\n    refund_window: 24h\n")
+		args := []string{"-config=" + fileName, "http"}
+		config, _, err := Load(args)
+		require.NoError(t, err)
+		runtime := NewRuntime(config.Runtime)
+		watcher := NewWatcher(args, config, runtime)
+		require.NoError(t, os.WriteFile(fileName, []byte("runtime:\n  policy:\n    refund_window: -1h\n"), 0600))
+
+		// act
+		err = watcher.Reload()
+
+		// assert
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "refund window must be positive")
+		assert.Equal(t, 24*time.Hour, runtime.Policies().Default.RefundWindow)
+	})
+}
+
+func TestRuntimeConfig_Validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		modify  func(runtimeConfig *RuntimeConfig)
+		wantErr string
+	}{
+		{
+			name:   "defaults test",
+			modify: func(runtimeConfig *RuntimeConfig) {},
+		},
+		{
+			name:    "log level test",
+			modify:  func(runtimeConfig *RuntimeConfig) { runtimeConfig.LogLevel = "loud" },
+			wantErr: "invalid log level \"loud\": expected debug, info, warn or error",
+		},
+		{
+			name:    "negative packaging test",
+			modify:  func(runtimeConfig *RuntimeConfig) { runtimeConfig.Packaging.Film.ExtraCost = -1 },
+			wantErr: "film packaging rule must not be negative",
+		},
+		{
+			name: "rate limit without burst test",
+			modify: func(runtimeConfig *RuntimeConfig) {
+				runtimeConfig.RateLimit.RequestsPerSecond = 10
+				runtimeConfig.RateLimit.Burst = 0
+			},
+			wantErr: "rate limit burst must be positive",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// arrange
+			runtimeConfig := NewRuntimeConfig()
+			tt.modify(runtimeConfig)
+
+			// act
+			err := runtimeConfig.Validate()
+
+			// assert
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
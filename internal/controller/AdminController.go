@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"GOHW-1/internal/model"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultStatsWindow  = 5 * time.Minute
+	maxStatsWindow      = time.Hour
+	defaultHistoryLimit = 100
+)
+
+type ConsumerControl interface {
+	Pause()
+	Resume()
+	Paused() bool
+	Connected() bool
+}
+
+type StatsProvider interface {
+	Stats(window time.Duration) []model.RouteStats
+	History(ctx context.Context, route string, limit int) ([]model.RouteStats, error)
+}
+
+// AdminController serves operational endpoints under /admin
+type AdminController struct {
+	Consumer ConsumerControl
+	Stats    StatsProvider
+}
+
+func NewAdminController(consumer ConsumerControl) *AdminController {
+	return &AdminController{Consumer: consumer}
+}
+
+type consumerStatus struct {
+	Connected bool `json:"connected"`
+	Paused    bool `json:"paused"`
+}
+
+// ConsumerStatus returns json with the state of the kafka consumer
+func (controller *AdminController) ConsumerStatus(w http.ResponseWriter, _ *http.Request) {
+	if controller.Consumer == nil {
+		http.Error(w, "consumer is not running", http.StatusNotFound)
+		return
+	}
+	controller.writeConsumerStatus(w)
+}
+
+// PauseConsumer stops consumption of kafka messages
+func (controller *AdminController) PauseConsumer(w http.ResponseWriter, _ *http.Request) {
+	if controller.Consumer == nil {
+		http.Error(w, "consumer is not running", http.StatusNotFound)
+		return
+	}
+	controller.Consumer.Pause()
+	controller.writeConsumerStatus(w)
+}
+
+// ResumeConsumer continues consumption of kafka messages
+func (controller *AdminController) ResumeConsumer(w http.ResponseWriter, _ *http.Request) {
+	if controller.Consumer == nil {
+		http.Error(w, "consumer is not running", http.StatusNotFound)
+		return
+	}
+	controller.Consumer.Resume()
+	controller.writeConsumerStatus(w)
+}
+
+func (controller *AdminController) writeConsumerStatus(w http.ResponseWriter) {
+	statusJson, _ := json.Marshal(consumerStatus{
+		Connected: controller.Consumer.Connected(),
+		Paused:    controller.Consumer.Paused(),
+	})
+	w.Write(statusJson)
+}
+
+// RequestStats returns json with per-route request statistics over the `window` query parameter (default 5m)
+func (controller *AdminController) RequestStats(w http.ResponseWriter, req *http.Request) {
+	if controller.Stats == nil {
+		http.Error(w, "request stats are not collected", http.StatusNotFound)
+		return
+	}
+
+	window := defaultStatsWindow
+	if windowStr := req.URL.Query().Get("window"); windowStr != "" {
+		parsed, err := time.ParseDuration(windowStr)
+		if err != nil || parsed <= 0 || parsed > maxStatsWindow {
+			http.Error(w, fmt.Sprintf("window must be a duration up to %s", maxStatsWindow), http.StatusBadRequest)
+			return
+		}
+		window = parsed
+	}
+
+	statsJson, _ := json.Marshal(controller.Stats.Stats(window))
+	w.Write(statsJson)
+}
+
+// RequestStatsHistory returns json with persisted request statistics, filtered by the `route` query parameter
+func (controller *AdminController) RequestStatsHistory(w http.ResponseWriter, req *http.Request) {
+	if controller.Stats == nil {
+		http.Error(w, "request stats are not collected", http.StatusNotFound)
+		return
+	}
+
+	limit := defaultHistoryLimit
+	if limitStr := req.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	history, err := controller.Stats.History(req.Context(), req.URL.Query().Get("route"), limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error occured: %v", err), http.StatusInternalServerError)
+		return
+	}
+	historyJson, _ := json.Marshal(history)
+	w.Write(historyJson)
+}
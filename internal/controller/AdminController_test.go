@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"GOHW-1/internal/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeConsumer struct {
+	paused bool
+}
+
+func (c *fakeConsumer) Pause()          { c.paused = true }
+func (c *fakeConsumer) Resume()         { c.paused = false }
+func (c *fakeConsumer) Paused() bool    { return c.paused }
+func (c *fakeConsumer) Connected() bool { return true }
+
+var httpConfig = *configuration.NewHTTPConfig()
+
+func Test_AdminConsumer(t *testing.T) {
+	t.Parallel()
+	t.Run("pause and resume test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		consumer := &fakeConsumer{}
+		router := createRouter(PickUpPointController{Sender: NopSender{}, HTTP: httpConfig, Admin: NewAdminController(consumer)})
+
+		// act
+		pause := httptest.NewRecorder()
+		pauseReq := httptest.NewRequest(http.MethodPost, "/admin/consumer/pause", nil)
+		pauseReq.SetBasicAuth(httpConfig.Username, httpConfig.Password)
+		router.ServeHTTP(pause, pauseReq)
+		isPausedAfterPause := consumer.paused
+
+		resume := httptest.NewRecorder()
+		resumeReq := httptest.NewRequest(http.MethodPost, "/admin/consumer/resume", nil)
+		resumeReq.SetBasicAuth(httpConfig.Username, httpConfig.Password)
+		router.ServeHTTP(resume, resumeReq)
+
+		// assert
+		require.Equal(t, http.StatusOK, pause.Code)
+		assert.True(t, isPausedAfterPause)
+		assert.Equal(t, "{\"connected\":true,\"paused\":true}", pause.Body.String())
+		require.Equal(t, http.StatusOK, resume.Code)
+		assert.False(t, consumer.paused)
+	})
+	t.Run("no consumer test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		router := createRouter(PickUpPointController{Sender: NopSender{}, HTTP: httpConfig, Admin: NewAdminController(nil)})
+		req := httptest.NewRequest(http.MethodGet, "/admin/consumer", nil)
+		req.SetBasicAuth(httpConfig.Username, httpConfig.Password)
+		w := httptest.NewRecorder()
+
+		// act
+		router.ServeHTTP(w, req)
+
+		// assert
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"GOHW-1/internal/db"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// healthCheckTimeout limits a dependency check, so that a hanging dependency does not hang the probe
+const healthCheckTimeout = 2 * time.Second
+
+// Statuses of the health endpoints
+const (
+	healthOK          = "ok"
+	healthDegraded    = "degraded"
+	healthUnavailable = "unavailable"
+)
+
+// Dependency is checked by the health endpoints. A failure of a critical dependency makes the server not ready,
+// a failure of another one only degrades it. Detail, if any, describes the dependency whatever its status is
+type Dependency struct {
+	Name     string
+	Critical bool
+	Check    func(ctx context.Context) error
+	Detail   func() string
+}
+
+type dependencyStatus struct {
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+	Error    string `json:"error,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+type healthResponse struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]dependencyStatus `json:"dependencies,omitempty"`
+}
+
+// DatabaseDependency reports the last PostgreSQL health check, the database is pinged in the background
+func DatabaseDependency(name string, health *db.Health, critical bool) Dependency {
+	return Dependency{Name: name, Critical: critical, Check: func(_ context.Context) error {
+		_, err := health.Status()
+		return err
+	}}
+}
+
+// ProducerDependency reports whether events are produced to kafka and how many events wait in the disk buffer.
+// A sender other than KafkaSender means that kafka could not be reached at startup and events go to the fallback sink
+func ProducerDependency(name string, sender Sender, critical bool) Dependency {
+	kafkaSender, ok := sender.(*KafkaSender)
+	if !ok {
+		return Dependency{Name: name, Critical: critical, Check: func(_ context.Context) error {
+			return errors.New("kafka was unavailable at startup, events go to the fallback sink")
+		}}
+	}
+	return Dependency{Name: name, Critical: critical,
+		Check: func(_ context.Context) error {
+			if !kafkaSender.Connected() {
+				return errors.New("kafka brokers are unreachable or fail to produce events")
+			}
+			return nil
+		},
+		Detail: func() string {
+			return fmt.Sprintf("%d events buffered on disk", kafkaSender.Buffered())
+		},
+	}
+}
+
+// ConsumerDependency reports whether the consumer is a member of its consumer group
+func ConsumerDependency(name string, consumer *Consumer, critical bool) Dependency {
+	return Dependency{Name: name, Critical: critical, Check: func(_ context.Context) error {
+		if !consumer.Member() {
+			return errors.New("not a member of the consumer group")
+		}
+		return nil
+	}}
+}
+
+// Liveness reports that the server handles requests. Dependencies are listed, but their failures do not fail
+// the probe, so that an orchestrator does not restart the server while a dependency is down
+func (controller *PickUpPointController) Liveness(w http.ResponseWriter, req *http.Request) {
+	response := controller.checkDependencies(req.Context())
+	writeHealth(w, response, http.StatusOK)
+}
+
+// Readiness reports whether the server can handle requests, i.e. no critical dependency failed.
+// Probes are not authenticated, so that load balancers and orchestrators can call them
+func (controller *PickUpPointController) Readiness(w http.ResponseWriter, req *http.Request) {
+	response := controller.checkDependencies(req.Context())
+	status := http.StatusOK
+	if response.Status == healthUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+	writeHealth(w, response, status)
+}
+
+// checkDependencies checks all dependencies at once, each within healthCheckTimeout
+func (controller *PickUpPointController) checkDependencies(ctx context.Context) healthResponse {
+	response := healthResponse{Status: healthOK}
+	if len(controller.Dependencies) == 0 {
+		return response
+	}
+
+	errs := make([]error, len(controller.Dependencies))
+	var wg sync.WaitGroup
+	for i, dependency := range controller.Dependencies {
+		wg.Add(1)
+		go func(i int, dependency Dependency) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+			errs[i] = dependency.Check(checkCtx)
+		}(i, dependency)
+	}
+	wg.Wait()
+
+	response.Dependencies = make(map[string]dependencyStatus, len(controller.Dependencies))
+	for i, dependency := range controller.Dependencies {
+		status := dependencyStatus{Status: healthOK, Critical: dependency.Critical}
+		if dependency.Detail != nil {
+			status.Detail = dependency.Detail()
+		}
+		if errs[i] != nil {
+			status.Status, status.Error = healthUnavailable, errs[i].Error()
+			if dependency.Critical {
+				response.Status = healthUnavailable
+			} else if response.Status == healthOK {
+				response.Status = healthDegraded
+			}
+		}
+		response.Dependencies[dependency.Name] = status
+	}
+	return response
+}
+
+func writeHealth(w http.ResponseWriter, response healthResponse, status int) {
+	responseJson, _ := json.Marshal(response)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(responseJson)
+}
@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"GOHW-1/internal/configuration"
+	"GOHW-1/internal/db"
+	"GOHW-1/internal/logger"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) Ping(ctx context.Context) error {
+	return f(ctx)
+}
+
+func Test_Readiness(t *testing.T) {
+	t.Parallel()
+	t.Run("smoke test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		router := createRouter(PickUpPointController{HTTP: httpConfig})
+		w := httptest.NewRecorder()
+
+		// act
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		// assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `{"status":"ok"}`, w.Body.String())
+	})
+	t.Run("database unavailable test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		health := db.NewHealth(pingerFunc(func(ctx context.Context) error { return errors.New("connection refused") }), 0, logger.Discard())
+		health.Check(context.Background())
+		dependencies := []Dependency{DatabaseDependency("postgres", health, true)}
+		router := createRouter(PickUpPointController{HTTP: httpConfig, Dependencies: dependencies})
+		w := httptest.NewRecorder()
+
+		// act
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		// assert
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, `{"status":"unavailable","dependencies":{"postgres":{"status":"unavailable","critical":true,"error":"connection refused"}}}`,
+			w.Body.String())
+	})
+	t.Run("non-critical failure test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		dependencies := []Dependency{
+			{Name: "postgres", Critical: true, Check: func(context.Context) error { return nil }},
+			ProducerDependency("kafka_producer", NewMemorySender(), false),
+		}
+		router := createRouter(PickUpPointController{HTTP: httpConfig, Dependencies: dependencies})
+		w := httptest.NewRecorder()
+
+		// act
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		// assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `{"status":"degraded","dependencies":{"kafka_producer":{"status":"unavailable","critical":false,`+
+			`"error":"kafka was unavailable at startup, events go to the fallback sink"},"postgres":{"status":"ok","critical":true}}}`,
+			w.Body.String())
+	})
+	t.Run("buffered events test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		senderConfig := configuration.NewSenderConfig()
+		kafkaConfig := configuration.NewKafkaConfig()
+		kafkaConfig.Brokers = []string{unreachableBroker(t)}
+		kafkaConfig.BufferDir = filepath.Join(t.TempDir(), "kafka_buffer")
+		sender, err := NewSender(senderConfig, kafkaConfig, logger.Discard())
+		require.NoError(t, err)
+		defer sender.Close()
+		require.NoError(t, sender.SendAsyncMessage(context.Background(), LoggingMessage{Method: http.MethodGet}))
+		dependencies := []Dependency{ProducerDependency("kafka_producer", sender, true)}
+		router := createRouter(PickUpPointController{HTTP: httpConfig, Dependencies: dependencies})
+		w := httptest.NewRecorder()
+
+		// act
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		// assert
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, `{"status":"unavailable","dependencies":{"kafka_producer":{"status":"unavailable","critical":true,`+
+			`"error":"kafka brokers are unreachable or fail to produce events","detail":"1 events buffered on disk"}}}`,
+			w.Body.String())
+	})
+	t.Run("hanging check test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		dependencies := []Dependency{{Name: "storage", Critical: true, Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}}}
+		pickUpPointController := PickUpPointController{Dependencies: dependencies}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		w := httptest.NewRecorder()
+
+		// act
+		pickUpPointController.Readiness(w, httptest.NewRequest(http.MethodGet, "/readyz", nil).WithContext(ctx))
+
+		// assert
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), `"error":"context canceled"`)
+	})
+	t.Run("api still authenticated test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		router := createRouter(PickUpPointController{HTTP: httpConfig})
+		w := httptest.NewRecorder()
+
+		// act
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pick-up-point", nil))
+
+		// assert
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func Test_Liveness(t *testing.T) {
+	t.Parallel()
+	t.Run("critical failure test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		dependencies := []Dependency{{Name: "postgres", Critical: true, Check: func(context.Context) error {
+			return errors.New("connection refused")
+		}}}
+		router := createRouter(PickUpPointController{HTTP: httpConfig, Dependencies: dependencies})
+		w := httptest.NewRecorder()
+
+		// act
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		// assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `"status":"unavailable"`)
+	})
+}
+
+func Test_Metrics(t *testing.T) {
+	t.Parallel()
+	t.Run("smoke test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		router := createRouter(PickUpPointController{HTTP: httpConfig})
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/pick-up-point/1", nil))
+		w := httptest.NewRecorder()
+
+		// act
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		// assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `gohw_http_requests_total{method="DELETE",route="/pick-up-point/{key:[0-9]+}",status="401"}`)
+		assert.NotContains(t, w.Body.String(), `route="/metrics"`)
+	})
+}
@@ -1,35 +1,263 @@
 package controller
 
 import (
+	"GOHW-1/internal/configuration"
+	"GOHW-1/internal/logger"
+	"GOHW-1/internal/metrics"
+	"GOHW-1/internal/tracing"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+	"math"
+	"net"
 	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
 	"time"
 )
 
-// LoggingMiddleware logs API queries
+// rateLimitIdle is how long the token bucket of a client is kept after its last request
+const rateLimitIdle = 10 * time.Minute
+
+// routeVariablePattern matches a route variable with its pattern, e.g. {key:[0-9]+}
+var routeVariablePattern = regexp.MustCompile(`\{(\w+):[^}]+\}`)
+
+// statusRecorder remembers the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	recorder.status = status
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+// RequestIDMiddleware reuses a valid X-Request-ID of the request or generates one, returns it in the response
+// and puts it into the request context, so that logs, queries and events of the request can be correlated
+func (controller *PickUpPointController) RequestIDMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requestID := req.Header.Get(logger.RequestIDHeader)
+		if !logger.ValidRequestID(requestID) {
+			requestID = logger.NewRequestID()
+		}
+		w.Header().Set(logger.RequestIDHeader, requestID)
+		handler.ServeHTTP(w, req.WithContext(logger.WithRequestID(req.Context(), requestID)))
+	})
+}
+
+// LoggingMiddleware logs API queries with their status and duration and sends them as logging messages.
+// A failure to send the logging message does not fail the request
 func (controller *PickUpPointController) LoggingMiddleware(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		err := controller.Sender.sendAsyncMessage(LoggingMessage{
-			Method: req.Method,
-			URI:    req.RequestURI,
-			Time:   time.Now(),
-		})
-		if err != nil {
-			http.Error(w, "cannot send the logging message", http.StatusInternalServerError)
-			return
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		handler.ServeHTTP(recorder, req)
+
+		ctx := req.Context()
+		message := LoggingMessage{
+			Method:    req.Method,
+			URI:       req.RequestURI,
+			Route:     routeTemplate(req),
+			Status:    recorder.status,
+			Duration:  time.Since(start),
+			Time:      start,
+			RequestID: logger.RequestID(ctx),
+		}
+		controller.logger().InfoContext(ctx, "request", "method", message.Method, "uri", message.URI,
+			"status", message.Status, "duration", message.Duration)
+		if err := controller.Sender.SendAsyncMessage(ctx, message); err != nil {
+			controller.logger().WarnContext(ctx, "cannot send the logging message", "error", err)
 		}
+	})
+}
 
-		handler.ServeHTTP(w, req)
+// MetricsMiddleware counts API requests and their latency by route template and status
+func (controller *PickUpPointController) MetricsMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		handler.ServeHTTP(recorder, req)
+
+		metrics.ObserveRequest(req.Method, routeTemplate(req), recorder.status, time.Since(start))
+	})
+}
+
+// TracingMiddleware continues the trace of W3C trace context headers of the request (or starts a new one)
+// with a server span named after the route template, so spans of queries and kafka messages become its children
+func (controller *PickUpPointController) TracingMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		route := routeTemplate(req)
+		ctx := tracing.Propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+		ctx, span := tracing.Tracer().Start(ctx, req.Method+" "+route, trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPRequestMethodKey.String(req.Method), semconv.HTTPRoute(route),
+				semconv.URLPath(req.URL.Path), attribute.String("request_id", logger.RequestID(ctx))))
+		defer span.End()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		handler.ServeHTTP(recorder, req.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
 	})
 }
 
+// routeTemplate returns the matched route pattern (e.g. /pick-up-point/{key}) so that requests
+// to different IDs are aggregated together
+func routeTemplate(req *http.Request) string {
+	if route := mux.CurrentRoute(req); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return req.URL.Path
+}
+
+// routeName returns the route template without patterns of its variables (e.g. /pick-up-point/{key}),
+// the way routes are named in settings
+func routeName(req *http.Request) string {
+	return routeVariablePattern.ReplaceAllString(routeTemplate(req), "{$1}")
+}
+
 // AuthMiddleware authenticate user
-func AuthMiddleware(handler http.Handler) http.Handler {
+func (controller *PickUpPointController) AuthMiddleware(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		username, password, ok := req.BasicAuth()
-		if !ok || username != configUsername || password != configPassword {
+		if !ok || !controller.authenticated(username, password) {
 			http.Error(w, "authentication failed", http.StatusUnauthorized)
 			return
 		}
 		handler.ServeHTTP(w, req)
 	})
 }
+
+func (controller *PickUpPointController) authenticated(username string, password string) bool {
+	return username == controller.HTTP.Username && password == controller.HTTP.Password
+}
+
+// clientKey tells clients apart for the rate limit: authenticated users by name, others by IP address.
+// Credentials are checked, so that a client cannot get fresh token buckets by sending other user names
+func (controller *PickUpPointController) clientKey(req *http.Request) string {
+	if username, password, ok := req.BasicAuth(); ok && controller.authenticated(username, password) {
+		return "user:" + username
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// BodyLimitMiddleware rejects request bodies larger than HTTP.MaxBodySize with 413 before handlers decode them
+func (controller *PickUpPointController) BodyLimitMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		maxBodySize := int64(controller.HTTP.MaxBodySize)
+		if maxBodySize > 0 {
+			if req.ContentLength > maxBodySize {
+				http.Error(w, fmt.Sprintf("request body is larger than %d bytes", maxBodySize), http.StatusRequestEntityTooLarge)
+				return
+			}
+			// bodies of unknown length are cut when the limit is reached, see readErrorStatus
+			req.Body = http.MaxBytesReader(w, req.Body, maxBodySize)
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// readErrorStatus returns the status of a failure to read the request body
+func readErrorStatus(err error) int {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// bucketKey identifies the token bucket of a client for a route
+type bucketKey struct {
+	client string
+	route  string
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// rateLimiter gives every client a token bucket per route with limits from runtime settings, so limits can be
+// changed by a configuration reload. Buckets of clients idle for rateLimitIdle are dropped
+type rateLimiter struct {
+	runtime   *configuration.Runtime
+	clientKey func(req *http.Request) string
+	config    *configuration.RuntimeConfig
+	buckets   map[bucketKey]*bucket
+	lastSweep time.Time
+	mutex     sync.Mutex
+}
+
+func newRateLimiter(runtime *configuration.Runtime, clientKey func(req *http.Request) string) *rateLimiter {
+	return &rateLimiter{runtime: runtime, clientKey: clientKey}
+}
+
+// reserve takes a token from the bucket of the client for the route. When no token is left,
+// it returns how long the client has to wait for one
+func (l *rateLimiter) reserve(client string, method string, route string, now time.Time) (time.Duration, bool) {
+	config := l.runtime.Get()
+	limit := config.RateLimit.For(method, route)
+	if limit.RequestsPerSecond <= 0 {
+		return 0, true
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.config != config {
+		// settings were reloaded, buckets start again with new limits
+		l.config, l.buckets = config, make(map[bucketKey]*bucket)
+	}
+	if now.Sub(l.lastSweep) >= rateLimitIdle {
+		for key, idle := range l.buckets {
+			if now.Sub(idle.lastSeen) >= rateLimitIdle {
+				delete(l.buckets, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	key := bucketKey{client: client, route: method + " " + route}
+	clientBucket, ok := l.buckets[key]
+	if !ok {
+		clientBucket = &bucket{limiter: rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), limit.Burst)}
+		l.buckets[key] = clientBucket
+	}
+	clientBucket.lastSeen = now
+
+	reservation := clientBucket.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return delay, false
+	}
+	return 0, true
+}
+
+// Middleware rejects requests above the rate limit with 429 and Retry-After in seconds
+func (l *rateLimiter) Middleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if delay, ok := l.reserve(l.clientKey(req), req.Method, routeName(req), time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
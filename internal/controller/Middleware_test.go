@@ -0,0 +1,333 @@
+package controller
+
+import (
+	"GOHW-1/internal/configuration"
+	"GOHW-1/internal/logger"
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type failingSender struct{}
+
+func (failingSender) SendAsyncMessage(_ context.Context, _ LoggingMessage) error {
+	return errors.New("kafka is down")
+}
+
+func (failingSender) Close() error {
+	return nil
+}
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func notFoundHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+}
+
+func Test_LoggingMiddleware(t *testing.T) {
+	t.Parallel()
+	t.Run("smoke test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		sender := NewMemorySender()
+		pickUpPointController := PickUpPointController{Sender: sender}
+		req := httptest.NewRequest(http.MethodGet, "/pick-up-point", nil)
+		w := httptest.NewRecorder()
+
+		// act
+		pickUpPointController.LoggingMiddleware(okHandler()).ServeHTTP(w, req)
+
+		// assert
+		require.Equal(t, http.StatusOK, w.Code)
+		messages := sender.Messages()
+		require.Len(t, messages, 1)
+		assert.Equal(t, http.MethodGet, messages[0].Method)
+		assert.Equal(t, "/pick-up-point", messages[0].URI)
+		assert.Equal(t, http.StatusOK, messages[0].Status)
+	})
+	t.Run("error status test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		sender := NewMemorySender()
+		pickUpPointController := PickUpPointController{Sender: sender}
+		req := httptest.NewRequest(http.MethodGet, "/pick-up-point/9999", nil)
+		w := httptest.NewRecorder()
+
+		// act
+		pickUpPointController.LoggingMiddleware(notFoundHandler()).ServeHTTP(w, req)
+
+		// assert
+		messages := sender.Messages()
+		require.Len(t, messages, 1)
+		assert.Equal(t, http.StatusNotFound, messages[0].Status)
+		assert.Equal(t, "/pick-up-point/9999", messages[0].Route)
+	})
+	t.Run("route template test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		sender := NewMemorySender()
+		pickUpPointController := PickUpPointController{Sender: sender}
+		router := mux.NewRouter()
+		router.Use(pickUpPointController.LoggingMiddleware)
+		router.Handle("/pick-up-point/{key:[0-9]+}", notFoundHandler()).Methods(http.MethodGet)
+
+		// act
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/pick-up-point/1", nil))
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/pick-up-point/9999", nil))
+
+		// assert
+		messages := sender.Messages()
+		require.Len(t, messages, 2)
+		assert.Equal(t, "/pick-up-point/1", messages[0].URI)
+		assert.Equal(t, "/pick-up-point/9999", messages[1].URI)
+		assert.Equal(t, "/pick-up-point/{key:[0-9]+}", messages[0].Route)
+		assert.Equal(t, "/pick-up-point/{key:[0-9]+}", messages[1].Route)
+	})
+	t.Run("failing sender test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pickUpPointController := PickUpPointController{Sender: failingSender{}}
+		req := httptest.NewRequest(http.MethodDelete, "/pick-up-point/1", nil)
+		w := httptest.NewRecorder()
+
+		// act
+		pickUpPointController.LoggingMiddleware(okHandler()).ServeHTTP(w, req)
+
+		// assert
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+	t.Run("request id test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		sender := NewMemorySender()
+		pickUpPointController := PickUpPointController{Sender: sender, Logger: logger.Discard()}
+		req := httptest.NewRequest(http.MethodGet, "/pick-up-point", nil)
+		req.Header.Set(logger.RequestIDHeader, "abc-123")
+		w := httptest.NewRecorder()
+		handler := pickUpPointController.RequestIDMiddleware(pickUpPointController.LoggingMiddleware(okHandler()))
+
+		// act
+		handler.ServeHTTP(w, req)
+
+		// assert
+		messages := sender.Messages()
+		require.Len(t, messages, 1)
+		assert.Equal(t, "abc-123", messages[0].RequestID)
+	})
+}
+
+func Test_RequestIDMiddleware(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		requestID string
+		reused    bool
+	}{
+		{name: "generated test", requestID: "", reused: false},
+		{name: "reused test", requestID: "3f2a-client.request_1", reused: true},
+		{name: "invalid test", requestID: "bad id\nheader", reused: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// arrange
+			pickUpPointController := PickUpPointController{}
+			var contextID string
+			handler := pickUpPointController.RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				contextID = logger.RequestID(req.Context())
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/pick-up-point", nil)
+			if tt.requestID != "" {
+				req.Header.Set(logger.RequestIDHeader, tt.requestID)
+			}
+			w := httptest.NewRecorder()
+
+			// act
+			handler.ServeHTTP(w, req)
+
+			// assert
+			responseID := w.Header().Get(logger.RequestIDHeader)
+			require.True(t, logger.ValidRequestID(responseID))
+			assert.Equal(t, responseID, contextID)
+			assert.Equal(t, tt.reused, responseID == tt.requestID)
+		})
+	}
+}
+
+func Test_TracingMiddleware(t *testing.T) {
+	t.Parallel()
+	t.Run("incoming trace test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pickUpPointController := PickUpPointController{}
+		var spanContext trace.SpanContext
+		handler := pickUpPointController.TracingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			spanContext = trace.SpanContextFromContext(req.Context())
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/pick-up-point", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		w := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(w, req)
+
+		// assert
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spanContext.TraceID().String())
+		assert.True(t, spanContext.IsSampled())
+	})
+	t.Run("no trace test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pickUpPointController := PickUpPointController{}
+		var spanContext trace.SpanContext
+		handler := pickUpPointController.TracingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			spanContext = trace.SpanContextFromContext(req.Context())
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/pick-up-point", nil)
+		w := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(w, req)
+
+		// assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, spanContext.IsValid())
+	})
+}
+
+func Test_rateLimiter(t *testing.T) {
+	t.Parallel()
+	t.Run("reload test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		runtimeConfig := configuration.NewRuntimeConfig()
+		runtimeConfig.RateLimit = configuration.RateLimitConfig{RequestsPerSecond: 0.001, Burst: 1}
+		runtime := configuration.NewRuntime(*runtimeConfig)
+		handler := newRateLimiter(runtime, (&PickUpPointController{}).clientKey).Middleware(okHandler())
+		serve := func() int {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pick-up-point", nil))
+			return w.Code
+		}
+
+		// act
+		first, second := serve(), serve()
+		runtimeConfig.RateLimit = configuration.RateLimitConfig{}
+		runtime.Store(*runtimeConfig)
+		afterReload := serve()
+
+		// assert
+		assert.Equal(t, http.StatusOK, first)
+		assert.Equal(t, http.StatusTooManyRequests, second)
+		assert.Equal(t, http.StatusOK, afterReload)
+	})
+	t.Run("per client test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		runtimeConfig := configuration.NewRuntimeConfig()
+		runtimeConfig.RateLimit = configuration.RateLimitConfig{RequestsPerSecond: 0.5, Burst: 1}
+		pickUpPointController := PickUpPointController{HTTP: httpConfig}
+		handler := newRateLimiter(configuration.NewRuntime(*runtimeConfig), pickUpPointController.clientKey).Middleware(okHandler())
+		serve := func(remoteAddr string, username string, password string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/pick-up-point", nil)
+			req.RemoteAddr = remoteAddr
+			if username != "" {
+				req.SetBasicAuth(username, password)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			return w
+		}
+
+		// act
+		first := serve("10.0.0.1:5000", "", "")
+		limited := serve("10.0.0.1:5001", "", "")
+		spoofed := serve("10.0.0.1:5002", "someone", "guess")
+		otherIP := serve("10.0.0.2:5000", "", "")
+		user := serve("10.0.0.1:5003", httpConfig.Username, httpConfig.Password)
+
+		// assert
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+		assert.Equal(t, "2", limited.Header().Get("Retry-After"))
+		assert.Equal(t, http.StatusTooManyRequests, spoofed.Code)
+		assert.Equal(t, http.StatusOK, otherIP.Code)
+		assert.Equal(t, http.StatusOK, user.Code)
+	})
+	t.Run("route limit test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		runtimeConfig := configuration.NewRuntimeConfig()
+		runtimeConfig.RateLimit = configuration.RateLimitConfig{Routes: map[string]configuration.RouteRateLimitConfig{
+			"POST /pick-up-point": {RequestsPerSecond: 0.001, Burst: 1},
+		}}
+		router := mux.NewRouter()
+		router.Use(newRateLimiter(configuration.NewRuntime(*runtimeConfig), (&PickUpPointController{}).clientKey).Middleware)
+		router.Handle("/pick-up-point", okHandler())
+		serve := func(method string) int {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(method, "/pick-up-point", nil))
+			return w.Code
+		}
+
+		// act
+		firstPost, secondPost := serve(http.MethodPost), serve(http.MethodPost)
+		firstGet, secondGet := serve(http.MethodGet), serve(http.MethodGet)
+
+		// assert
+		assert.Equal(t, http.StatusOK, firstPost)
+		assert.Equal(t, http.StatusTooManyRequests, secondPost)
+		assert.Equal(t, http.StatusOK, firstGet)
+		assert.Equal(t, http.StatusOK, secondGet)
+	})
+}
+
+func Test_BodyLimitMiddleware(t *testing.T) {
+	t.Parallel()
+	t.Run("content length test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pickUpPointController := PickUpPointController{HTTP: configuration.HTTPConfig{MaxBodySize: 8}}
+		handlerCalled := false
+		handler := pickUpPointController.BodyLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			handlerCalled = true
+		}))
+		w := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/pick-up-point", strings.NewReader(`{"name":"too long"}`)))
+
+		// assert
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.False(t, handlerCalled)
+	})
+	t.Run("unknown length test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pickUpPointController := PickUpPointController{HTTP: configuration.HTTPConfig{MaxBodySize: 8}}
+		handler := pickUpPointController.BodyLimitMiddleware(http.HandlerFunc(pickUpPointController.Create))
+		req := httptest.NewRequest(http.MethodPost, "/pick-up-point", strings.NewReader(`{"name":"too long"}`))
+		req.ContentLength = -1
+		w := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(w, req)
+
+		// assert
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+}
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec describes every route of createRouter, OpenAPI_test keeps them in sync
+//
+//go:embed openapi/openapi.json
+var openAPISpec []byte
+
+// docsPage renders openAPISpec in a browser, it has no external scripts, so it works offline
+//
+//go:embed openapi/docs.html
+var docsPage []byte
+
+// OpenAPI serves the OpenAPI 3 specification of the HTTP API
+func (controller *PickUpPointController) OpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPISpec)
+}
+
+// Docs serves the documentation page of the HTTP API
+func (controller *PickUpPointController) Docs(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(docsPage)
+}
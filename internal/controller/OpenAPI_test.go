@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"GOHW-1/internal/model"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+type openAPIDocument struct {
+	OpenAPI    string                                `json:"openapi"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+func parseOpenAPI(t *testing.T) openAPIDocument {
+	t.Helper()
+	var document openAPIDocument
+	require.NoError(t, json.Unmarshal(openAPISpec, &document))
+	return document
+}
+
+// specOperations lists operations of the spec as "METHOD /path"
+func specOperations(document openAPIDocument) []string {
+	var operations []string
+	for path, item := range document.Paths {
+		for method := range item {
+			if method == "parameters" {
+				continue
+			}
+			operations = append(operations, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(operations)
+	return operations
+}
+
+// routerOperations lists routes of the router as "METHOD /path" with patterns of variables removed
+func routerOperations(t *testing.T, router *mux.Router) []string {
+	t.Helper()
+	var operations []string
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if route.GetHandler() == nil {
+			return nil
+		}
+		template, err := route.GetPathTemplate()
+		require.NoError(t, err)
+		methods, err := route.GetMethods()
+		if err != nil {
+			// a route without methods answers other methods of its path with 405
+			return nil
+		}
+		for _, method := range methods {
+			operations = append(operations, method+" "+routeVariablePattern.ReplaceAllString(template, "{$1}"))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	sort.Strings(operations)
+	return operations
+}
+
+// jsonKeys returns the keys of value marshalled to a JSON object
+func jsonKeys(t *testing.T, value any) []string {
+	t.Helper()
+	body, err := json.Marshal(value)
+	require.NoError(t, err)
+	var object map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(body, &object))
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func Test_OpenAPI(t *testing.T) {
+	t.Parallel()
+	t.Run("router and spec in sync test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		document := parseOpenAPI(t)
+		router := createRouter(PickUpPointController{HTTP: httpConfig, Admin: &AdminController{}})
+
+		// act
+		routes := routerOperations(t, router)
+		operations := specOperations(document)
+
+		// assert
+		assert.Equal(t, "3.0.3", document.OpenAPI)
+		assert.Equal(t, routes, operations)
+	})
+	t.Run("schemas in sync test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		document := parseOpenAPI(t)
+		tests := []struct {
+			schema string
+			value  any
+		}{
+			{"PickUpPoint", model.PickUpPoint{}},
+			{"RouteStats", model.RouteStats{ComputedAt: time.Now()}},
+			{"ConsumerStatus", consumerStatus{}},
+			{"Health", healthResponse{Dependencies: map[string]dependencyStatus{"postgres": {}}}},
+			{"DependencyStatus", dependencyStatus{Error: "error", Detail: "detail"}},
+		}
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.schema, func(t *testing.T) {
+				t.Parallel()
+				// act
+				schema, ok := document.Components.Schemas[tt.schema]
+				properties := make([]string, 0, len(schema.Properties))
+				for property := range schema.Properties {
+					properties = append(properties, property)
+				}
+				sort.Strings(properties)
+
+				// assert
+				require.True(t, ok)
+				assert.Equal(t, jsonKeys(t, tt.value), properties)
+			})
+		}
+	})
+	t.Run("serve test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		router := createRouter(PickUpPointController{HTTP: httpConfig})
+
+		// act
+		spec := httptest.NewRecorder()
+		router.ServeHTTP(spec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+		docs := httptest.NewRecorder()
+		router.ServeHTTP(docs, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+		// assert
+		assert.Equal(t, http.StatusOK, spec.Code)
+		assert.Equal(t, "application/json", spec.Header().Get("Content-Type"))
+		assert.JSONEq(t, string(openAPISpec), spec.Body.String())
+		assert.Equal(t, http.StatusOK, docs.Code)
+		assert.Contains(t, docs.Body.String(), `fetch("/openapi.json")`)
+	})
+	t.Run("method not allowed test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		sender := NewMemorySender()
+		router := createRouter(PickUpPointController{HTTP: httpConfig, Sender: sender})
+		req := httptest.NewRequest(http.MethodPatch, "/pick-up-point/1", nil)
+		req.SetBasicAuth(httpConfig.Username, httpConfig.Password)
+
+		// act
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// assert
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		messages := sender.Messages()
+		require.Len(t, messages, 1)
+		assert.Equal(t, http.StatusMethodNotAllowed, messages[0].Status)
+		assert.Equal(t, "/pick-up-point/{key:[0-9]+}", messages[0].Route)
+	})
+	t.Run("unauthenticated method test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		router := createRouter(PickUpPointController{HTTP: httpConfig})
+
+		// act
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/pick-up-point", nil))
+
+		// assert
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
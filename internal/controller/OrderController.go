@@ -1,12 +1,20 @@
 package controller
 
 import (
+	"GOHW-1/internal/cli"
+	"GOHW-1/internal/clock"
+	"GOHW-1/internal/configuration"
 	"GOHW-1/internal/model"
+	"GOHW-1/internal/output"
 	"GOHW-1/internal/service"
-	"bufio"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -14,8 +22,29 @@ import (
 
 type OrderController struct {
 	service *service.Service
+	runtime *configuration.Runtime
+	clock   clock.Clock
+	logger  *slog.Logger
 	wg      *sync.WaitGroup
 	ctx     context.Context
+	out     io.Writer
+}
+
+var orderColumns = []output.Column[model.Order]{
+	{Name: "id", Value: func(order model.Order) any { return order.ID }},
+	{Name: "client_id", Value: func(order model.Order) any { return order.ClientID }},
+	{Name: "expiration_date", Value: func(order model.Order) any { return order.ExpirationDate }},
+	{Name: "weight", Value: func(order model.Order) any { return order.Weight }},
+	{Name: "price", Value: func(order model.Order) any { return order.Price }},
+	{Name: "packaging", Value: func(order model.Order) any { return order.Packaging }},
+	{Name: "pick_up_point_id", Value: func(order model.Order) any { return order.PickUpPointID }},
+}
+
+var pickUpPointColumns = []output.Column[model.PickUpPoint]{
+	{Name: "id", Value: func(pickUpPoint model.PickUpPoint) any { return pickUpPoint.ID }},
+	{Name: "name", Value: func(pickUpPoint model.PickUpPoint) any { return pickUpPoint.Name }},
+	{Name: "address", Value: func(pickUpPoint model.PickUpPoint) any { return pickUpPoint.Address }},
+	{Name: "contact", Value: func(pickUpPoint model.PickUpPoint) any { return pickUpPoint.Contact }},
 }
 
 type ReadRequest struct {
@@ -27,157 +56,253 @@ type WriteRequest struct {
 	ResponseChan chan error
 }
 
-func NewOrderController(svc *service.Service, wg *sync.WaitGroup, ctx context.Context) *OrderController {
+func NewOrderController(svc *service.Service, runtime *configuration.Runtime, clock clock.Clock, logger *slog.Logger,
+	wg *sync.WaitGroup, ctx context.Context) *OrderController {
 	return &OrderController{
 		service: svc,
+		runtime: runtime,
+		clock:   clock,
+		logger:  logger,
 		wg:      wg,
 		ctx:     ctx,
+		out:     os.Stdout,
+	}
+}
+
+// ArgumentError tells that an order operation got an incorrect argument, unlike operations rejected by the storage
+type ArgumentError struct {
+	message string
+}
+
+func (err *ArgumentError) Error() string {
+	return err.message
+}
+
+func argumentError(message string) error {
+	return &ArgumentError{message: message}
+}
+
+func (controller *OrderController) CourierTakeCommand(orderID int, clientID int, availableTime time.Duration, weight float64, price float64, packaging string, pickUpPointID int64) error {
+	if _, err := controller.TakeOrder(orderID, clientID, availableTime, weight, price, packaging, pickUpPointID); err != nil {
+		return err
 	}
+
+	fmt.Println("Courier's order accepted successfully!")
+	return nil
 }
 
-func (controller *OrderController) CourierTakeCommand(orderID int, clientID int, availableTime time.Duration, weight float64, price float64, packaging string) error {
+// TakeOrder accepts an order from the courier and returns it with the extra cost of its packaging
+func (controller *OrderController) TakeOrder(orderID int, clientID int, availableTime time.Duration, weight float64, price float64, packaging string, pickUpPointID int64) (model.Order, error) {
 	if orderID <= 0 {
-		return fmt.Errorf("order ID is not given or incorrect")
+		return model.Order{}, argumentError("order ID is not given or incorrect")
 	}
 	if clientID <= 0 {
-		return fmt.Errorf("client ID is not given or incorrect")
+		return model.Order{}, argumentError("client ID is not given or incorrect")
 	}
 	if availableTime <= 0 {
-		return fmt.Errorf("available time is not given or incorrect")
+		return model.Order{}, argumentError("available time is not given or incorrect")
+	}
+	if pickUpPointID < 0 {
+		return model.Order{}, argumentError("pick-up point ID is incorrect")
 	}
 
-	rules := model.GetPackagingRules()
+	rules := controller.runtime.Get().PackagingRules()
 	rule, ok := rules[packaging]
 
 	if !ok {
-		return fmt.Errorf("invalid packaging type")
+		return model.Order{}, argumentError("invalid packaging type")
 	}
 
 	if rule.MaxWeight > 0 && weight > rule.MaxWeight {
-		return fmt.Errorf("order weight exceeds limit for %s", packaging)
+		return model.Order{}, argumentError(fmt.Sprintf("order weight exceeds limit for %s", packaging))
 	}
 
 	price += rule.ExtraCost
 
-	if err := controller.service.CourierTakeOrder(model.Order{
+	order := model.Order{
 		ID:             orderID,
 		ClientID:       clientID,
-		ExpirationDate: time.Now().Add(availableTime),
+		ExpirationDate: controller.clock.Now().Add(availableTime),
 		Weight:         weight,
 		Price:          price,
 		Packaging:      packaging,
-	}); err != nil {
-		return fmt.Errorf("failed to take order from courier: %w", err)
+		PickUpPointID:  pickUpPointID,
+	}
+	if err := controller.service.CourierTakeOrder(order); err != nil {
+		return model.Order{}, fmt.Errorf("failed to take order from courier: %w", err)
 	}
+	return order, nil
+}
 
-	fmt.Println("Courier's order accepted successfully!")
+func (controller *OrderController) CourierReturnCommand(orderID int) error {
+	if err := controller.ReturnOrder(orderID); err != nil {
+		return err
+	}
+
+	fmt.Println("The order was returned to the courier successfully!")
 	return nil
 }
 
-func (controller *OrderController) CourierReturnCommand(orderID int) error {
+// ReturnOrder returns an expired order to the courier
+func (controller *OrderController) ReturnOrder(orderID int) error {
 	if orderID <= 0 {
-		return fmt.Errorf("order ID is not given or incorrect")
+		return argumentError("order ID is not given or incorrect")
 	}
 
 	if err := controller.service.CourierGiveOrder(orderID); err != nil {
 		return fmt.Errorf("failed to return order to courier: %w", err)
 	}
-
-	fmt.Println("The order was returned to the courier successfully!")
 	return nil
 }
 
 func (controller *OrderController) ClientGiveCommand(clientID int, ordersID string) error {
+	var ordersIDSlice []string
+	if ordersID != "" {
+		ordersIDSlice = strings.Split(ordersID, ",")
+	}
+	if err := controller.GiveOrders(clientID, ordersIDSlice); err != nil {
+		return err
+	}
+
+	fmt.Println("Orders were given to the client successfully!")
+	return nil
+}
+
+// GiveOrders gives orders to the client
+func (controller *OrderController) GiveOrders(clientID int, ordersID []string) error {
 	if clientID <= 0 {
-		return fmt.Errorf("client ID is not given or incorrect")
+		return argumentError("client ID is not given or incorrect")
 	}
-	if ordersID == "" {
-		return fmt.Errorf("orders ID are not given")
+	if len(ordersID) == 0 {
+		return argumentError("orders ID are not given")
 	}
-	ordersIDSlice := strings.Split(ordersID, ",")
 
-	if err := controller.service.ClientGiveOrder(clientID, ordersIDSlice); err != nil {
+	if err := controller.service.ClientGiveOrder(clientID, ordersID); err != nil {
 		return fmt.Errorf("failed to give order to client: %w", err)
 	}
-
-	fmt.Println("Orders were given to the client successfully!")
 	return nil
 }
 
-func (controller *OrderController) ClientOrdersCommand(clientID int, N int, onlyUserOrders bool) error {
+// ClientOrdersCommand prints orders of the client in the given output format
+func (controller *OrderController) ClientOrdersCommand(clientID int, N int, onlyUserOrders bool, format string) error {
+	if err := output.ValidateFormat(format); err != nil {
+		return err
+	}
+
+	orders, err := controller.ClientOrders(clientID, N, onlyUserOrders)
+	if err != nil {
+		return err
+	}
+
+	if len(orders) == 0 && format == output.Table {
+		fmt.Fprintln(controller.out, "List of orders is empty")
+		return nil
+	}
+	return output.Print(controller.out, format, orders, orderColumns)
+}
+
+// ClientOrders returns N last orders of the client (-1 - all of them)
+func (controller *OrderController) ClientOrders(clientID int, N int, onlyUserOrders bool) ([]model.Order, error) {
 	if clientID <= 0 {
-		return fmt.Errorf("client ID is not given or incorrect")
+		return nil, argumentError("client ID is not given or incorrect")
 	}
 	if N < -1 || N == 0 {
-		return fmt.Errorf("n value is incorrect")
+		return nil, argumentError("n value is incorrect")
 	}
 
 	orders, err := controller.service.ClientGetOrders(clientID, N, onlyUserOrders)
 	if err != nil {
-		return fmt.Errorf("failed to get orders for client: %w", err)
+		return nil, fmt.Errorf("failed to get orders for client: %w", err)
 	}
+	return orders, nil
+}
 
-	if len(orders) == 0 {
-		fmt.Println("List of orders is empty")
-		return nil
-	}
-	for _, order := range orders {
-		fmt.Printf("%+v\n", order)
+func (controller *OrderController) ClientRefundCommand(orderID int, clientID int) error {
+	if err := controller.RefundOrder(orderID, clientID); err != nil {
+		return err
 	}
+
+	fmt.Println("Order refunded successfully!")
 	return nil
 }
 
-func (controller *OrderController) ClientRefundCommand(orderID int, clientID int) error {
+// RefundOrder accepts a return of the order from the client
+func (controller *OrderController) RefundOrder(orderID int, clientID int) error {
 	if orderID <= 0 {
-		return fmt.Errorf("order ID is not given or incorrect")
+		return argumentError("order ID is not given or incorrect")
 	}
 	if clientID <= 0 {
-		return fmt.Errorf("client ID is not given or incorrect")
+		return argumentError("client ID is not given or incorrect")
 	}
 
 	if err := controller.service.ClientRefund(clientID, orderID); err != nil {
 		return fmt.Errorf("failed to refund order: %w", err)
 	}
-
-	fmt.Println("Order refunded successfully!")
 	return nil
 }
 
-func (controller *OrderController) RefundListCommand(pageNumber int) error {
-	if pageNumber <= 0 {
-		return fmt.Errorf("Page number is  incorrect")
+// RefundListCommand prints a page of refunded orders in the given output format
+func (controller *OrderController) RefundListCommand(pageNumber int, format string) error {
+	if err := output.ValidateFormat(format); err != nil {
+		return err
 	}
-	orders, err := controller.service.RefundList(pageNumber)
+	orders, err := controller.RefundList(pageNumber)
 	if err != nil {
-		return fmt.Errorf("failed to get refund list: %w", err)
+		return err
 	}
 
+	if format != output.Table {
+		return output.Print(controller.out, format, orders, orderColumns)
+	}
 	if len(orders) == 0 {
-		fmt.Println("List of orders is empty")
+		fmt.Fprintln(controller.out, "List of orders is empty")
 		return nil
 	}
-	fmt.Printf("\t\t\tPage number: %d\n", pageNumber)
-	for _, order := range orders {
-		fmt.Printf("%+v\n", order)
+	fmt.Fprintf(controller.out, "\t\t\tPage number: %d\n", pageNumber)
+	if err := output.Print(controller.out, format, orders, orderColumns); err != nil {
+		return err
 	}
-	fmt.Printf("\t\t\tPage number: %d\n", pageNumber)
+	fmt.Fprintf(controller.out, "\t\t\tPage number: %d\n", pageNumber)
 	return nil
 }
 
+// RefundList returns a page of refunded orders
+func (controller *OrderController) RefundList(pageNumber int) ([]model.Order, error) {
+	if pageNumber <= 0 {
+		return nil, argumentError("Page number is  incorrect")
+	}
+	orders, err := controller.service.RefundList(pageNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refund list: %w", err)
+	}
+	return orders, nil
+}
+
+// InteractiveCommand runs a shell that accepts every order command with the same flags as the command line,
+// plus read/write of pick-up points. It returns on `exit` or at the end of input
 func (controller *OrderController) InteractiveCommand() {
 	readRequests := make(chan ReadRequest)
 	writeRequests := make(chan WriteRequest)
 
+	ctx, cancel := context.WithCancel(controller.ctx)
+	var workers sync.WaitGroup
+	defer func() {
+		cancel()
+		workers.Wait()
+	}()
+
 	// Goroutine for reading
 	controller.wg.Add(1)
+	workers.Add(1)
 	go func(ctx context.Context) {
 		defer controller.wg.Done()
+		defer workers.Done()
 		for {
 			select {
 			case req := <-readRequests:
 				pickUpPoints, err := controller.service.PickUpPointsRead()
 				if err != nil {
-					fmt.Println(fmt.Errorf("failed to read pick-up points: %w", err))
+					controller.logger.ErrorContext(ctx, "failed to read pick-up points", "error", err)
 					close(req.ResponseChan)
 					continue
 				}
@@ -186,12 +311,14 @@ func (controller *OrderController) InteractiveCommand() {
 				return
 			}
 		}
-	}(controller.ctx)
+	}(ctx)
 
 	// Goroutine for writing
 	controller.wg.Add(1)
+	workers.Add(1)
 	go func(ctx context.Context) {
 		defer controller.wg.Done()
+		defer workers.Done()
 		for {
 			select {
 			case req := <-writeRequests:
@@ -201,30 +328,46 @@ func (controller *OrderController) InteractiveCommand() {
 				return
 			}
 		}
-	}(controller.ctx)
+	}(ctx)
 
-	reader := bufio.NewReader(os.Stdin)
+	commands := cli.NewRegistry("", OrderCommands(func() (*OrderController, error) { return controller, nil })...)
+	sh := newShell(os.Stdin, os.Stdout, commands)
 	for {
-		input, err := reader.ReadString('\n')
+		input, err := sh.ReadLine()
 		if err != nil {
-			fmt.Println(fmt.Errorf("failed to read input: %w", err))
-			continue
+			if !errors.Is(err, io.EOF) {
+				fmt.Println(fmt.Errorf("failed to read input: %w", err))
+			}
+			return
 		}
-		input = strings.TrimSpace(input)
 
 		parts := strings.SplitN(input, " ", 2)
 		command := parts[0]
 
-		switch command {
-		case "read":
+		switch {
+		case command == "read":
+			readFlags := flag.NewFlagSet("read", flag.ContinueOnError)
+			format := readFlags.String("output", output.Table, configuration.OutputUsage)
+			if err := readFlags.Parse(strings.Fields(input)[1:]); err != nil {
+				continue
+			}
+			if err := output.ValidateFormat(*format); err != nil {
+				fmt.Println(err)
+				continue
+			}
+
 			responseChan := make(chan []model.PickUpPoint)
 			readRequests <- ReadRequest{ResponseChan: responseChan}
 			pickUpPoints := <-responseChan
 
-			for _, p := range pickUpPoints {
-				fmt.Printf("%+v\n", p)
+			if len(pickUpPoints) == 0 && *format == output.Table {
+				fmt.Println("List of points is empty")
+				continue
+			}
+			if err := output.Print(controller.out, *format, pickUpPoints, pickUpPointColumns); err != nil {
+				fmt.Println(err)
 			}
-		case "write":
+		case command == "write":
 			if len(parts) < 2 {
 				fmt.Println("write command requires arguments")
 				continue
@@ -248,65 +391,41 @@ func (controller *OrderController) InteractiveCommand() {
 			} else {
 				fmt.Println("Pick-up point has been written successfully!")
 			}
-		case "exit":
-			controller.wg.Wait()
+		case command == "help":
+			interactiveHelp(commands, strings.Fields(input)[1:])
+		case command == "history":
+			for i, line := range sh.History() {
+				fmt.Printf("%4d  %s\n", i+1, line)
+			}
+		case command == "exit" || command == "quit":
 			return
 		default:
-			fmt.Println("unknown command. List of commands: \"read, write\"")
+			err := commands.Run(strings.Fields(input))
+			if errors.Is(err, flag.ErrHelp) {
+				commands.PrintHelp(os.Stdout, command)
+			} else if err != nil {
+				fmt.Println(err)
+			}
 		}
 	}
 }
 
-func (controller *OrderController) HelpCommand() {
-	fmt.Print("COMMANDS" +
-		"\n  cr-take\n" +
-		"\tAccepts and writes an order from the courier into a file\n" +
-		"\tRequired flags: -cid, -oid, -at\n\n" +
-		"\tCannot accept order twice or with a negative available time\n" +
-		"\tExample of using: `cr-take -cid=1 -oid=1 -at=48h`\n" +
-		"\n  cr-return\n" +
-		"\tReturns an order to the courier (deletes the order from file)\n" +
-		"\tRequired flag: -oid\n\n" +
-		"\tCan return orders only with an expiration date in the past and those\n" +
-		"\tthat have not been given\n" +
-		"\tExample of using: `cr-return -oid=1`\n" +
-		"\n  cl-give\n" +
-		"\tGives one or more orders to the client\n" +
-		"\tRequired flags: -cid, -oids\n\n" +
-		"\tOnly orders accepted from the courier (present in the file) with expiration\n" +
-		"\tdates earlier than the current date can be given. All order IDs must \n" +
-		"\tbelong to the same client.\n" +
-		"\tExample of using: `cl-give -cid=1 -oids=1,3,7`\n" +
-		"\n  cl-orders\n" +
-		"\tGet orders\n" +
-		"\tRequired flag: -cid\n" +
-		"\tOptional flags: -n, -ouo\n\n" +
-		"\tExample of using: `cl-orders -cid=1 [-n=5] [-ouo]`\n" +
-		"\n  cl-refund\n" +
-		"\tAccepts a return from the client (move an order from available orders to refunded orders)\n" +
-		"\tRequired flags: -cid, -oid\n\n" +
-		"\tOrders can be returned within 2 days from the given date.\n" +
-		"\tExample of using: `cl-refund -cid=1 -oid=1\n" +
-		"\n  refund-list\n" +
-		"\tProvides the list of refunds in a paginated manner.\n" +
-		"\tOptional flag: -p\n\n" +
-		"\tExample of using: `refund-list [-p=3]`\n" +
-		"\n  interactive\n" +
-		"\tLaunches an interactive mode that has two commands: write and read\n\n" +
-		"\tExample of using: `write Pick-up point #1, Tomorrow Avenue, +78005553535`\n\n" +
-		"FLAGS" +
-		"\n  -cid int\n" +
-		"\tClient ID\n" +
-		"\n  -at duration\n" +
-		"\tAvailable time for picking up the order (e.g. -at=48h)\n" +
-		"\n  -n int\n" +
-		"\tHow many last orders the user will receive (default -1)\n" +
-		"\n  -oid int\n" +
-		"\tOrder ID\n" +
-		"\n  -oids string\n" +
-		"\tComma-separated slice of ordersID (e.g. -oids=1,3,7)\n" +
-		"\n  -ouo\n" +
-		"\tGet only user orders\n" +
-		"\n  -p int\n" +
-		"\tPage number starting with 1 (default 1)")
+// interactiveHelp prints commands of the interactive mode or help of the given command
+func interactiveHelp(commands *cli.Registry, args []string) {
+	if len(args) > 0 {
+		if slices.Contains(shellCommands, args[0]) {
+			fmt.Printf("%s is a command of the interactive mode, see `help`\n", args[0])
+		} else if err := commands.PrintHelp(os.Stdout, args[0]); err != nil {
+			fmt.Println(err)
+		}
+		return
+	}
+
+	commands.PrintUsage(os.Stdout)
+	fmt.Println("\nINTERACTIVE COMMANDS")
+	fmt.Println("  read [-output=format]              shows all pick-up points")
+	fmt.Println("  write Name, Address, Contact info  writes a pick-up point")
+	fmt.Println("  history                            shows entered commands")
+	fmt.Println("  exit                               leaves the interactive mode (also Ctrl-D)")
+	fmt.Println("\nTab completes commands and flags, arrow keys navigate the history")
 }
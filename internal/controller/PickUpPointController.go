@@ -1,7 +1,9 @@
 package controller
 
 import (
+	"GOHW-1/internal/configuration"
 	"GOHW-1/internal/db"
+	"GOHW-1/internal/metrics"
 	"GOHW-1/internal/model"
 	"GOHW-1/internal/repository/postgresql"
 	"context"
@@ -11,18 +13,13 @@ import (
 	"fmt"
 	"github.com/gorilla/mux"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
 	"strconv"
 )
 
-const (
-	queryParamKey  = "key"
-	configUsername = "ildus"
-	configPassword = "erbaev"
-	securePort     = ":9001"
-	insecurePort   = ":9000"
-)
+const queryParamKey = "key"
 
 //go:generate mockgen -package controller -destination=./mocks/mock_repository.go . PickUpPointsRepo
 type PickUpPointsRepo interface {
@@ -34,83 +31,137 @@ type PickUpPointsRepo interface {
 }
 
 type Sender interface {
-	sendAsyncMessage(message LoggingMessage) error
+	SendAsyncMessage(ctx context.Context, message LoggingMessage) error
+	Close() error
 }
 
 type PickUpPointController struct {
 	Repo   PickUpPointsRepo
 	Sender Sender
+	Admin  *AdminController
+	HTTP   configuration.HTTPConfig
+	// Runtime holds reloadable settings such as the rate limit, nil means no limit
+	Runtime *configuration.Runtime
+	// Dependencies are checked by the health endpoints, none means always ready
+	Dependencies []Dependency
+	// Logger logs requests and errors of handlers, nil means the default logger
+	Logger *slog.Logger
 }
 
-func NewPickUpPointController(database *db.Database, sender *KafkaSender) *PickUpPointController {
+func NewPickUpPointController(database *db.Database, sender Sender) *PickUpPointController {
 	pickUpPointRepo := postgresql.NewPickUpPoints(*database)
-	//sender := NewKafkaSender(producer, topic)
 
 	return &PickUpPointController{
 		Repo:   pickUpPointRepo,
 		Sender: sender,
+		HTTP:   *configuration.NewHTTPConfig(),
+		Logger: slog.Default(),
 	}
 }
 
 func (controller *PickUpPointController) StartHTTPServer() {
 	http.Handle("/", createRouter(*controller))
 	go func() {
-		if err := http.ListenAndServeTLS(securePort, "./server.crt", "./server.key", nil); err != nil {
-			fmt.Println(fmt.Errorf("cannot handle: %w", err))
+		if err := http.ListenAndServeTLS(controller.HTTP.SecurePort, controller.HTTP.CertFile, controller.HTTP.KeyFile, nil); err != nil {
+			controller.logger().Error("HTTPS server ListenAndServeTLS", "error", err)
 			return
 		}
 	}()
 
-	redirectToHTTPS()
+	controller.redirectToHTTPS()
 }
 
-func redirectToHTTPS() {
+func (controller *PickUpPointController) redirectToHTTPS() {
 	serveMux := http.NewServeMux()
 	serveMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		secureURL := "https://" + r.Host + securePort + r.RequestURI
+		secureURL := "https://" + r.Host + controller.HTTP.SecurePort + r.RequestURI
 		http.Redirect(w, r, secureURL, http.StatusMovedPermanently)
 	})
 
-	if err := http.ListenAndServe(insecurePort, nil); err != nil {
-		log.Fatalf("HTTP server ListenAndServe: %v", err)
+	if err := http.ListenAndServe(controller.HTTP.InsecurePort, nil); err != nil {
+		controller.logger().Error("HTTP server ListenAndServe", "error", err)
+		os.Exit(1)
 	}
 }
 
 func createRouter(controller PickUpPointController) *mux.Router {
-	router := mux.NewRouter()
-	router.Use(AuthMiddleware)
+	root := mux.NewRouter()
+	// probes, metrics and docs are registered before the API, so they skip its rate limit, authentication and logging
+	root.HandleFunc("/healthz", controller.Liveness).Methods(http.MethodGet)
+	root.HandleFunc("/readyz", controller.Readiness).Methods(http.MethodGet)
+	root.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
+	root.HandleFunc("/openapi.json", controller.OpenAPI).Methods(http.MethodGet)
+	root.HandleFunc("/docs", controller.Docs).Methods(http.MethodGet)
+	// only other methods of the probes, metrics and docs get here, those of the API are answered by the API router
+	root.MethodNotAllowedHandler = http.HandlerFunc(methodNotAllowed)
+
+	// the API router matches any request by itself, routes of a path prefix subrouter would lose their 405
+	router := root.NewRoute().Subrouter()
+	router.Use(controller.RequestIDMiddleware)
+	router.Use(controller.TracingMiddleware)
+	// requests rejected by the rate limit or authentication are counted too
+	router.Use(controller.MetricsMiddleware)
+	if controller.Runtime != nil {
+		router.Use(newRateLimiter(controller.Runtime, controller.clientKey).Middleware)
+	}
+	router.Use(controller.BodyLimitMiddleware)
+	router.Use(controller.AuthMiddleware)
 	router.Use(controller.LoggingMiddleware)
-	router.HandleFunc("/pick-up-point", func(w http.ResponseWriter, req *http.Request) {
-		switch req.Method {
-		case http.MethodPost:
-			controller.Create(w, req)
-		case http.MethodGet:
-			controller.List(w, req)
-		default:
-			http.Error(w, "method is not implemented", http.StatusMethodNotAllowed)
-		}
-	})
+	router.HandleFunc("/pick-up-point", controller.Create).Methods(http.MethodPost)
+	router.HandleFunc("/pick-up-point", controller.List).Methods(http.MethodGet)
+
+	if controller.Admin != nil {
+		router.HandleFunc("/admin/consumer", controller.Admin.ConsumerStatus).Methods(http.MethodGet)
+		router.HandleFunc("/admin/consumer/pause", controller.Admin.PauseConsumer).Methods(http.MethodPost)
+		router.HandleFunc("/admin/consumer/resume", controller.Admin.ResumeConsumer).Methods(http.MethodPost)
+		router.HandleFunc("/admin/stats", controller.Admin.RequestStats).Methods(http.MethodGet)
+		router.HandleFunc("/admin/stats/history", controller.Admin.RequestStatsHistory).Methods(http.MethodGet)
+	}
+
+	pickUpPointPath := fmt.Sprintf("/pick-up-point/{%s:[0-9]+}", queryParamKey)
+	router.HandleFunc(pickUpPointPath, controller.GetByID).Methods(http.MethodGet)
+	router.HandleFunc(pickUpPointPath, controller.Delete).Methods(http.MethodDelete)
+	router.HandleFunc(pickUpPointPath, controller.Update).Methods(http.MethodPut)
+
+	// other methods of the API routes are answered by the API router, so that they are authenticated, rate limited,
+	// counted and logged like any API request
+	for _, template := range pathTemplates(router) {
+		router.HandleFunc(template, methodNotAllowed)
+	}
+	return root
+}
 
-	router.HandleFunc(fmt.Sprintf("/pick-up-point/{%s:[0-9]+}", queryParamKey), func(w http.ResponseWriter, req *http.Request) {
-		switch req.Method {
-		case http.MethodGet:
-			controller.GetByID(w, req)
-		case http.MethodDelete:
-			controller.Delete(w, req)
-		case http.MethodPut:
-			controller.Update(w, req)
-		default:
-			http.Error(w, "method is not implemented", http.StatusMethodNotAllowed)
+// pathTemplates returns distinct path templates of routes of the router in the order they were registered
+func pathTemplates(router *mux.Router) []string {
+	var templates []string
+	seen := make(map[string]bool)
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if template, err := route.GetPathTemplate(); err == nil && !seen[template] {
+			seen[template] = true
+			templates = append(templates, template)
 		}
+		return nil
 	})
-	return router
+	return templates
+}
+
+// methodNotAllowed answers requests of known routes with a method they do not handle
+func methodNotAllowed(w http.ResponseWriter, _ *http.Request) {
+	http.Error(w, "method is not implemented", http.StatusMethodNotAllowed)
+}
+
+func (controller *PickUpPointController) logger() *slog.Logger {
+	if controller.Logger == nil {
+		return slog.Default()
+	}
+	return controller.Logger
 }
 
 // Create handles the creation of a new pick-up point
 func (controller *PickUpPointController) Create(w http.ResponseWriter, req *http.Request) {
 	body, err := io.ReadAll(req.Body)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("error occured: %v", err), http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf("error occured: %v", err), readErrorStatus(err))
 		return
 	}
 	var request model.PickUpPoint
@@ -137,6 +188,7 @@ func (controller *PickUpPointController) CreatePickUpPoint(ctx context.Context,
 
 	id, err := controller.Repo.Create(ctx, pickUpPointRepo)
 	if err != nil {
+		controller.logger().ErrorContext(ctx, "cannot create pick-up point", "error", err)
 		return nil, http.StatusInternalServerError, fmt.Errorf("can not created pick-up point")
 	}
 
@@ -178,6 +230,7 @@ func (controller *PickUpPointController) GetJSONByID(ctx context.Context, idStr
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, http.StatusNotFound, fmt.Errorf("pick-up point not found")
 		}
+		controller.logger().ErrorContext(ctx, "cannot get pick-up point", "id", id, "error", err)
 		return nil, http.StatusNotFound, fmt.Errorf("error occured: %v", err)
 	}
 	pickUpPointJson, _ := json.Marshal(pickUpPoint)
@@ -188,6 +241,7 @@ func (controller *PickUpPointController) GetJSONByID(ctx context.Context, idStr
 func (controller *PickUpPointController) List(w http.ResponseWriter, req *http.Request) {
 	pickUpPoints, err := controller.Repo.List(req.Context())
 	if err != nil {
+		controller.logger().ErrorContext(req.Context(), "cannot list pick-up points", "error", err)
 		http.Error(w, fmt.Sprintf("error occured: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -206,7 +260,7 @@ func (controller *PickUpPointController) Update(w http.ResponseWriter, req *http
 
 	body, err := io.ReadAll(req.Body)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("error occured: %v", err), http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf("error occured: %v", err), readErrorStatus(err))
 		return
 	}
 
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"GOHW-1/internal/cli"
+	"GOHW-1/internal/configuration"
+	"flag"
+)
+
+// OrderControllerProvider returns the controller that runs order commands. It is called only when a command runs,
+// so listing commands (e.g. for help) does not open the storage
+type OrderControllerProvider func() (*OrderController, error)
+
+// OrderCommands returns order commands in the order they are shown in help
+func OrderCommands(provide OrderControllerProvider) []cli.Command {
+	return []cli.Command{
+		{
+			Name:        "cr-take",
+			Summary:     "Accepts and writes an order from the courier into a file",
+			Description: "Cannot accept order twice, with a negative available time or longer than the storage limit of its pick-up point.\n-pg accepts: package, carton, film",
+			Required:    []string{"oid", "cid", "at", "pg"},
+			Example:     "cr-take -cid=1 -oid=1 -at=48h -pr=100 -w=0.5 -pg=carton",
+			Flags:       func(config *configuration.AppConfig) *flag.FlagSet { return &config.CrTake.FlagSet },
+			Run: func(config *configuration.AppConfig, _ []string) error {
+				controller, err := provide()
+				if err != nil {
+					return err
+				}
+				return controller.CourierTakeCommand(*config.CrTake.OrderID, *config.CrTake.ClientID, *config.CrTake.AvailableTime,
+					*config.CrTake.Weight, *config.CrTake.Price, *config.CrTake.Packaging, *config.CrTake.PickUpPointID)
+			},
+		},
+		{
+			Name:        "cr-return",
+			Summary:     "Returns an order to the courier (deletes the order from file)",
+			Description: "Can return orders only with an expiration date in the past and those that have not been given.",
+			Required:    []string{"oid"},
+			Example:     "cr-return -oid=1",
+			Flags:       func(config *configuration.AppConfig) *flag.FlagSet { return &config.CrReturn.FlagSet },
+			Run: func(config *configuration.AppConfig, _ []string) error {
+				controller, err := provide()
+				if err != nil {
+					return err
+				}
+				return controller.CourierReturnCommand(*config.CrReturn.OrderID)
+			},
+		},
+		{
+			Name:    "cl-give",
+			Summary: "Gives one or more orders to the client",
+			Description: "Only orders accepted from the courier with expiration dates earlier than the current date can be given.\n" +
+				"All order IDs must belong to the same client.",
+			Required: []string{"cid", "oids"},
+			Example:  "cl-give -cid=1 -oids=1,3,7",
+			Flags:    func(config *configuration.AppConfig) *flag.FlagSet { return &config.ClGive.FlagSet },
+			Run: func(config *configuration.AppConfig, _ []string) error {
+				controller, err := provide()
+				if err != nil {
+					return err
+				}
+				return controller.ClientGiveCommand(*config.ClGive.ClientID, *config.ClGive.OrdersID)
+			},
+		},
+		{
+			Name:     "cl-orders",
+			Summary:  "Get orders of the client",
+			Required: []string{"cid"},
+			Example:  "cl-orders -cid=1 -n=5 -ouo -output=json",
+			Flags:    func(config *configuration.AppConfig) *flag.FlagSet { return &config.ClOrders.FlagSet },
+			Run: func(config *configuration.AppConfig, _ []string) error {
+				controller, err := provide()
+				if err != nil {
+					return err
+				}
+				return controller.ClientOrdersCommand(*config.ClOrders.ClientID, *config.ClOrders.N, *config.ClOrders.OnlyUserOrders,
+					*config.ClOrders.Output)
+			},
+		},
+		{
+			Name:        "cl-refund",
+			Summary:     "Accepts a return from the client (move an order from available orders to refunded orders)",
+			Description: "Orders can be returned within 2 days from the given date.",
+			Required:    []string{"cid", "oid"},
+			Example:     "cl-refund -cid=1 -oid=1",
+			Flags:       func(config *configuration.AppConfig) *flag.FlagSet { return &config.ClRefund.FlagSet },
+			Run: func(config *configuration.AppConfig, _ []string) error {
+				controller, err := provide()
+				if err != nil {
+					return err
+				}
+				return controller.ClientRefundCommand(*config.ClRefund.OrderID, *config.ClRefund.ClientID)
+			},
+		},
+		{
+			Name:    "refund-list",
+			Summary: "Provides the list of refunds in a paginated manner",
+			Example: "refund-list -p=3 -output=csv",
+			Flags:   func(config *configuration.AppConfig) *flag.FlagSet { return &config.RefundList.FlagSet },
+			Run: func(config *configuration.AppConfig, _ []string) error {
+				controller, err := provide()
+				if err != nil {
+					return err
+				}
+				return controller.RefundListCommand(*config.RefundList.PageNumber, *config.RefundList.Output)
+			},
+		},
+	}
+}
@@ -1,10 +1,13 @@
 package controller
 
 import (
+	"GOHW-1/internal/configuration"
 	"GOHW-1/internal/infrastucture/kafka"
 	"context"
+	"errors"
+	"fmt"
 	"github.com/IBM/sarama"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"sync"
@@ -12,82 +15,162 @@ import (
 	"time"
 )
 
-func ConsumerGroup(brokers []string, topicName string) {
-	keepRunning := true
-	log.Println("Starting a new Sarama consumer...")
-
-	config := sarama.NewConfig()
-	config.Version = sarama.MaxVersion
-
-	config.Consumer.Offsets.Initial = sarama.OffsetNewest
-	config.Consumer.Group.ResetInvalidOffsets = true
-
-	config.Consumer.Group.Heartbeat.Interval = 3 * time.Second
-	config.Consumer.Group.Session.Timeout = time.Minute
-	config.Consumer.Group.Rebalance.Timeout = time.Minute
+const (
+	consumerMinBackoff = time.Second
+	consumerMaxBackoff = 30 * time.Second
+)
 
-	config.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.BalanceStrategyRoundRobin}
+// Consumer reads the logging topic as a member of the consumer group. It reconnects with backoff
+// while kafka is unavailable and can be paused and resumed at runtime
+type Consumer struct {
+	kafkaConfig *configuration.KafkaConfig
+	handler     kafka.MessageHandler
+	logger      *slog.Logger
+	client      sarama.ConsumerGroup
+	group       *kafka.ConsumerGroup
+	isPaused    bool
+	mutex       sync.Mutex
+}
 
-	consumer := kafka.NewConsumerGroup()
-	group := "route"
+func NewConsumer(kafkaConfig *configuration.KafkaConfig, handler kafka.MessageHandler, logger *slog.Logger) *Consumer {
+	return &Consumer{kafkaConfig: kafkaConfig, handler: handler, logger: logger}
+}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	client, err := sarama.NewConsumerGroup(brokers, group, config)
+// Run consumes messages until the context is cancelled. SIGUSR1 toggles consumption
+func (c *Consumer) Run(ctx context.Context) error {
+	config, err := kafka.NewConfig(c.kafkaConfig)
 	if err != nil {
-		log.Panicf("error creating consumer group client: %v", err)
+		return fmt.Errorf("error creating consumer group config: %w", err)
 	}
-	defer client.Close()
 
-	consumptionIsPaused := false
-	wg := &sync.WaitGroup{}
-	wg.Add(1)
+	sigusr1 := make(chan os.Signal, 1)
+	signal.Notify(sigusr1, syscall.SIGUSR1)
+	defer signal.Stop(sigusr1)
 	go func() {
-		defer wg.Done()
 		for {
-			if err := client.Consume(ctx, []string{topicName}, &consumer); err != nil {
-				log.Panicf("error from consumer: %v", err)
-			}
-			// Check if context was cancelled, signaling that the consumer should stop
-			if ctx.Err() != nil {
+			select {
+			case <-sigusr1:
+				c.Toggle()
+			case <-ctx.Done():
 				return
 			}
 		}
 	}()
 
-	<-consumer.Ready()
-	log.Println("Sarama consumer up and running!")
+	c.logger.Info("starting a new Sarama consumer", "topic", c.kafkaConfig.Topic, "group", c.kafkaConfig.GroupID)
+	backoff := consumerMinBackoff
+	for {
+		joined, err := c.consume(ctx, config)
+		if ctx.Err() != nil {
+			c.logger.Info("terminating: context cancelled")
+			return nil
+		}
+		if joined {
+			backoff = consumerMinBackoff
+		}
 
-	sigusr1 := make(chan os.Signal, 1)
-	signal.Notify(sigusr1, syscall.SIGUSR1)
+		c.logger.Warn("consumer group error, reconnecting", "backoff", backoff, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			c.logger.Info("terminating: context cancelled")
+			return nil
+		}
+		backoff = min(backoff*2, consumerMaxBackoff)
+	}
+}
 
-	sigterm := make(chan os.Signal, 1)
-	signal.Notify(sigterm, syscall.SIGINT, syscall.SIGTERM)
+// consume connects to kafka and consumes until the context is cancelled or the connection fails.
+// It reports whether a session was set up, a client that never joins the group does not reset the backoff
+func (c *Consumer) consume(ctx context.Context, config *sarama.Config) (bool, error) {
+	client, err := sarama.NewConsumerGroup(c.kafkaConfig.Brokers, c.kafkaConfig.GroupID, config)
+	if err != nil {
+		return false, fmt.Errorf("error creating consumer group client: %w", err)
+	}
+	consumer := kafka.NewConsumerGroup(c.handler, c.logger)
+	defer func() {
+		c.mutex.Lock()
+		c.client, c.group = nil, nil
+		c.mutex.Unlock()
+		client.Close()
+	}()
 
-	for keepRunning {
+	c.mutex.Lock()
+	c.client, c.group = client, consumer
+	if c.isPaused {
+		client.PauseAll()
+	}
+	c.mutex.Unlock()
+
+	go func() {
 		select {
+		case <-consumer.Ready():
+			c.logger.Info("Sarama consumer up and running")
 		case <-ctx.Done():
-			log.Println("terminating: context cancelled")
-			keepRunning = false
-		case <-sigterm:
-			log.Println("terminating: via signal")
-			keepRunning = false
-		case <-sigusr1:
-			toggleConsumptionFlow(client, &consumptionIsPaused)
 		}
+	}()
+
+	for {
+		if err := client.Consume(ctx, []string{c.kafkaConfig.Topic}, consumer); err != nil {
+			if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+				return consumer.Started(), nil
+			}
+			return consumer.Started(), fmt.Errorf("error from consumer: %w", err)
+		}
+		// Check if context was cancelled, signaling that the consumer should stop
+		if ctx.Err() != nil {
+			return consumer.Started(), nil
+		}
+	}
+}
+
+// Pause stops fetching messages until Resume is called
+func (c *Consumer) Pause() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.client != nil {
+		c.client.PauseAll()
 	}
+	c.isPaused = true
+	c.logger.Info("pausing consumption")
+}
 
-	cancel()
-	wg.Wait()
+// Resume continues fetching messages
+func (c *Consumer) Resume() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.client != nil {
+		c.client.ResumeAll()
+	}
+	c.isPaused = false
+	c.logger.Info("resuming consumption")
 }
 
-func toggleConsumptionFlow(client sarama.ConsumerGroup, isPaused *bool) {
-	if *isPaused {
-		client.ResumeAll()
-		log.Println("Resuming consumption")
+// Toggle pauses a running consumer and resumes a paused one
+func (c *Consumer) Toggle() {
+	if c.Paused() {
+		c.Resume()
 	} else {
-		client.PauseAll()
-		log.Println("Pausing consumption")
+		c.Pause()
 	}
+}
+
+func (c *Consumer) Paused() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.isPaused
+}
+
+// Connected reports whether the consumer currently has a consumer group client
+func (c *Consumer) Connected() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.client != nil
+}
 
-	*isPaused = !*isPaused
+// Member reports whether the consumer is a member of its consumer group with a running session
+func (c *Consumer) Member() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.group != nil && c.group.Member()
 }
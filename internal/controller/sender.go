@@ -1,17 +1,62 @@
 package controller
 
 import (
+	"GOHW-1/internal/configuration"
 	"GOHW-1/internal/infrastucture/kafka"
+	"GOHW-1/internal/logger"
+	"GOHW-1/internal/tracing"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
 	"time"
 )
 
 type LoggingMessage struct {
-	Method string
-	URI    string
-	Time   time.Time
+	Method    string
+	URI       string
+	Route     string
+	Status    int
+	Duration  time.Duration
+	Time      time.Time
+	RequestID string `json:",omitempty"`
+}
+
+// NewSender creates the event sink chosen in configuration. If kafka is chosen but cannot be reached and
+// its disk buffer is disabled, the fallback sink is used instead so that the application keeps working offline
+func NewSender(senderConfig *configuration.SenderConfig, kafkaConfig *configuration.KafkaConfig, logger *slog.Logger) (Sender, error) {
+	if senderConfig.Type != configuration.SenderKafka {
+		return newSenderByType(senderConfig.Type, senderConfig)
+	}
+
+	producer, err := kafka.NewProducer(kafkaConfig, logger)
+	if err != nil {
+		logger.Warn("cannot connect to kafka, falling back to another events sink", "sink", senderConfig.Fallback, "error", err)
+		return newSenderByType(senderConfig.Fallback, senderConfig)
+	}
+	return NewKafkaSender(producer, kafkaConfig.Topic), nil
+}
+
+func newSenderByType(senderType string, senderConfig *configuration.SenderConfig) (Sender, error) {
+	switch senderType {
+	case configuration.SenderFile:
+		return NewFileSender(senderConfig.FilePath)
+	case configuration.SenderStdout:
+		return NewWriterSender(os.Stdout), nil
+	case configuration.SenderMemory:
+		return NewMemorySender(), nil
+	case configuration.SenderNone:
+		return NopSender{}, nil
+	default:
+		return nil, fmt.Errorf("unknown events sink %q", senderType)
+	}
 }
 
 type KafkaSender struct {
@@ -26,35 +71,131 @@ func NewKafkaSender(producer *kafka.Producer, topic string) *KafkaSender {
 	}
 }
 
-func (s *KafkaSender) sendAsyncMessage(message LoggingMessage) error {
-	kafkaMsg, err := s.buildMessage(message)
+// SendAsyncMessage sends the message in a producer span, trace context of the span is sent in message headers
+func (s *KafkaSender) SendAsyncMessage(ctx context.Context, message LoggingMessage) error {
+	ctx, span := tracing.Tracer().Start(ctx, s.topic+" publish", trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(semconv.MessagingSystemKafka, semconv.MessagingOperationPublish, semconv.MessagingDestinationName(s.topic)))
+	defer span.End()
+
+	kafkaMsg, err := s.buildMessage(ctx, message)
+	if err == nil {
+		err = s.producer.SendAsyncMessage(kafkaMsg)
+	}
 	if err != nil {
-		fmt.Println("Send message marshal error", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
-
-	s.producer.SendAsyncMessage(kafkaMsg)
 	return nil
 }
 
-func (s *KafkaSender) buildMessage(message LoggingMessage) (*sarama.ProducerMessage, error) {
-	msg, err := json.Marshal(message)
+// Connected reports whether kafka brokers are reached and produce events
+func (s *KafkaSender) Connected() bool {
+	return s.producer.Connected()
+}
 
+// Buffered returns the number of events waiting on disk to be sent to kafka
+func (s *KafkaSender) Buffered() int {
+	return s.producer.Buffered()
+}
+
+func (s *KafkaSender) Close() error {
+	return s.producer.Close()
+}
+
+func (s *KafkaSender) buildMessage(ctx context.Context, message LoggingMessage) (*sarama.ProducerMessage, error) {
+	msg, err := json.Marshal(message)
 	if err != nil {
-		fmt.Println("Send message marshal error", err)
-		return nil, err
+		return nil, fmt.Errorf("cannot marshal the logging message: %w", err)
 	}
 
+	var headers []sarama.RecordHeader
+	if message.RequestID != "" {
+		headers = append(headers, sarama.RecordHeader{Key: []byte(logger.RequestIDHeader), Value: []byte(message.RequestID)})
+	}
+	headers = kafka.InjectTraceContext(ctx, headers)
 	return &sarama.ProducerMessage{
 		Topic:     s.topic,
 		Value:     sarama.ByteEncoder(msg),
 		Partition: -1,
 		Key:       sarama.StringEncoder(fmt.Sprint(message.Method + " " + message.URI)),
-		Headers: []sarama.RecordHeader{
-			{
-				Key:   []byte("test-header"),
-				Value: []byte("test-value"),
-			},
-		},
+		Headers:   headers,
 	}, nil
 }
+
+// WriterSender writes every message as a JSON line into the given writer
+type WriterSender struct {
+	writer io.Writer
+	mutex  sync.Mutex
+}
+
+func NewWriterSender(writer io.Writer) *WriterSender {
+	return &WriterSender{writer: writer}
+}
+
+func (s *WriterSender) SendAsyncMessage(_ context.Context, message LoggingMessage) error {
+	line, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err = s.writer.Write(append(line, '\n'))
+	return err
+}
+
+func (s *WriterSender) Close() error {
+	if closer, ok := s.writer.(io.Closer); ok && s.writer != os.Stdout {
+		return closer.Close()
+	}
+	return nil
+}
+
+// NewFileSender appends messages as JSON lines to the file with the given name
+func NewFileSender(fileName string) (*WriterSender, error) {
+	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open events file: %w", err)
+	}
+	return NewWriterSender(file), nil
+}
+
+// MemorySender keeps messages in memory, it is meant for tests
+type MemorySender struct {
+	messages []LoggingMessage
+	mutex    sync.Mutex
+}
+
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+func (s *MemorySender) SendAsyncMessage(_ context.Context, message LoggingMessage) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+// Messages returns a copy of all sent messages
+func (s *MemorySender) Messages() []LoggingMessage {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]LoggingMessage(nil), s.messages...)
+}
+
+func (s *MemorySender) Close() error {
+	return nil
+}
+
+// NopSender drops every message
+type NopSender struct{}
+
+func (NopSender) SendAsyncMessage(_ context.Context, _ LoggingMessage) error {
+	return nil
+}
+
+func (NopSender) Close() error {
+	return nil
+}
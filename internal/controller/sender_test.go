@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"GOHW-1/internal/configuration"
+	"GOHW-1/internal/logger"
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// unreachableBroker returns the address of a port nothing listens on
+func unreachableBroker(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	return address
+}
+
+func Test_NewSender(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		sink      string
+		fallback  string
+		bufferDir bool
+		want      Sender
+		wantErr   string
+	}{
+		{name: "file", sink: configuration.SenderFile, fallback: configuration.SenderNone, want: &WriterSender{}},
+		{name: "stdout", sink: configuration.SenderStdout, fallback: configuration.SenderNone, want: &WriterSender{}},
+		{name: "memory", sink: configuration.SenderMemory, fallback: configuration.SenderNone, want: &MemorySender{}},
+		{name: "none", sink: configuration.SenderNone, fallback: configuration.SenderFile, want: NopSender{}},
+		{name: "unknown", sink: "redis", fallback: configuration.SenderNone, wantErr: `unknown events sink "redis"`},
+		{name: "kafka unreachable falls back to file", sink: configuration.SenderKafka, fallback: configuration.SenderFile,
+			want: &WriterSender{}},
+		{name: "kafka unreachable falls back to memory", sink: configuration.SenderKafka, fallback: configuration.SenderMemory,
+			want: &MemorySender{}},
+		{name: "kafka unreachable with disk buffer", sink: configuration.SenderKafka, fallback: configuration.SenderFile,
+			bufferDir: true, want: &KafkaSender{}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name+" test", func(t *testing.T) {
+			t.Parallel()
+			// arrange
+			dir := t.TempDir()
+			senderConfig := &configuration.SenderConfig{Type: tt.sink, Fallback: tt.fallback,
+				FilePath: filepath.Join(dir, "events.jsonl")}
+			kafkaConfig := configuration.NewKafkaConfig()
+			kafkaConfig.Brokers = []string{unreachableBroker(t)}
+			kafkaConfig.BufferDir = ""
+			if tt.bufferDir {
+				kafkaConfig.BufferDir = filepath.Join(dir, "kafka_buffer")
+			}
+
+			// act
+			sender, err := NewSender(senderConfig, kafkaConfig, logger.Discard())
+
+			// assert
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			defer sender.Close()
+			assert.IsType(t, tt.want, sender)
+			if kafkaSender, ok := sender.(*KafkaSender); ok {
+				assert.False(t, kafkaSender.Connected())
+			}
+		})
+	}
+}
+
+func Test_FileSender(t *testing.T) {
+	t.Parallel()
+	t.Run("appends json lines test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		fileName := filepath.Join(t.TempDir(), "events.jsonl")
+		start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		messages := []LoggingMessage{
+			{Method: http.MethodGet, URI: "/pick-up-point", Route: "/pick-up-point", Status: http.StatusOK,
+				Duration: time.Millisecond, Time: start, RequestID: "first"},
+			{Method: http.MethodDelete, URI: "/pick-up-point/1", Route: "/pick-up-point/{key:[0-9]+}",
+				Status: http.StatusNotFound, Duration: 2 * time.Millisecond, Time: start.Add(time.Second)},
+			{Method: http.MethodPost, URI: "/pick-up-point", Route: "/pick-up-point", Status: http.StatusOK,
+				Duration: 3 * time.Millisecond, Time: start.Add(2 * time.Second)},
+		}
+
+		// act
+		first, err := NewFileSender(fileName)
+		require.NoError(t, err)
+		for _, message := range messages[:2] {
+			require.NoError(t, first.SendAsyncMessage(context.Background(), message))
+		}
+		require.NoError(t, first.Close())
+		// a sender opened later, e.g. after a restart, appends to the same file
+		second, err := NewFileSender(fileName)
+		require.NoError(t, err)
+		require.NoError(t, second.SendAsyncMessage(context.Background(), messages[2]))
+		require.NoError(t, second.Close())
+
+		// assert
+		file, err := os.Open(fileName)
+		require.NoError(t, err)
+		defer file.Close()
+		var written []LoggingMessage
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var message LoggingMessage
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &message), scanner.Text())
+			written = append(written, message)
+		}
+		require.NoError(t, scanner.Err())
+		assert.Equal(t, messages, written)
+	})
+	t.Run("unwritable file test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		fileName := filepath.Join(t.TempDir(), "missing", "events.jsonl")
+
+		// act
+		_, err := NewFileSender(fileName)
+
+		// assert
+		assert.ErrorContains(t, err, "cannot open events file")
+	})
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"GOHW-1/internal/cli"
+	"bufio"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+const shellPrompt = "> "
+
+// shellCommands are commands of the interactive mode besides order commands
+var shellCommands = []string{"exit", "help", "history", "read", "write"}
+
+// shell reads commands line by line. On a terminal it supports arrow-key history and tab completion,
+// otherwise (e.g. when input is piped) it reads plain lines
+type shell struct {
+	terminal *term.Terminal
+	scanner  *bufio.Scanner
+	fd       int
+	history  []string
+}
+
+func newShell(in *os.File, out io.Writer, commands *cli.Registry) *shell {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return &shell{scanner: bufio.NewScanner(in), fd: -1}
+	}
+
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{in, out}, shellPrompt)
+	terminal.AutoCompleteCallback = shellCompleter(commands)
+	return &shell{terminal: terminal, fd: fd}
+}
+
+// ReadLine returns the next non-empty line or io.EOF when input is over (Ctrl-D / Ctrl-C on a terminal)
+func (s *shell) ReadLine() (string, error) {
+	for {
+		line, err := s.readRawLine()
+		if err != nil {
+			return "", err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		s.history = append(s.history, line)
+		return line, nil
+	}
+}
+
+func (s *shell) readRawLine() (string, error) {
+	if s.terminal == nil {
+		if !s.scanner.Scan() {
+			if err := s.scanner.Err(); err != nil {
+				return "", err
+			}
+			return "", io.EOF
+		}
+		return s.scanner.Text(), nil
+	}
+
+	// The terminal is raw only while a line is edited, so command output is printed as usual
+	state, err := term.MakeRaw(s.fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(s.fd, state)
+	return s.terminal.ReadLine()
+}
+
+// History returns all lines entered in this session
+func (s *shell) History() []string {
+	return s.history
+}
+
+// shellCompleter returns a Tab handler that completes command names, flags of commands and arguments of help
+func shellCompleter(commands *cli.Registry) func(line string, pos int, key rune) (string, int, bool) {
+	return func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+
+		before, after := line[:pos], line[pos:]
+		fields := strings.Fields(before)
+		completingNewWord := len(fields) == 0 || strings.HasSuffix(before, " ")
+
+		var candidates []string
+		var prefix string
+		switch {
+		case len(fields) == 0 || (len(fields) == 1 && !completingNewWord):
+			candidates = append(commands.Names(), shellCommands...)
+			if len(fields) == 1 {
+				prefix = fields[0]
+			}
+		case fields[0] == "help":
+			candidates = commands.Names()
+		case fields[0] == "read":
+			candidates = []string{"-output="}
+		default:
+			candidates = commands.Candidates(fields[0])
+		}
+		if len(fields) > 0 && !completingNewWord && prefix == "" {
+			prefix = fields[len(fields)-1]
+		}
+
+		matches := make([]string, 0)
+		for _, candidate := range candidates {
+			if strings.HasPrefix(candidate, prefix) {
+				matches = append(matches, candidate)
+			}
+		}
+		if len(matches) == 0 {
+			return "", 0, false
+		}
+		sort.Strings(matches)
+
+		completion := commonPrefix(matches)
+		if len(matches) == 1 && !strings.HasSuffix(completion, "=") {
+			completion += " "
+		}
+		newBefore := before[:len(before)-len(prefix)] + completion
+		return newBefore + after, len(newBefore), true
+	}
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"GOHW-1/internal/cli"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_shellCompleter(t *testing.T) {
+	t.Parallel()
+	complete := shellCompleter(cli.NewRegistry("", OrderCommands(nil)...))
+	tests := []struct {
+		name     string
+		line     string
+		wantLine string
+		wantOk   bool
+	}{
+		{name: "unique command test", line: "cr-ta", wantLine: "cr-take ", wantOk: true},
+		{name: "common prefix test", line: "cl-", wantLine: "cl-", wantOk: true},
+		{name: "shell command test", line: "hi", wantLine: "history ", wantOk: true},
+		{name: "flag with value test", line: "cr-take -oi", wantLine: "cr-take -oid=", wantOk: true},
+		{name: "bool flag test", line: "cl-orders -cid=1 -ouo", wantLine: "cl-orders -cid=1 -ouo ", wantOk: true},
+		{name: "common flag prefix test", line: "cl-orders -cid=1 -o", wantLine: "cl-orders -cid=1 -ou", wantOk: true},
+		{name: "output flag test", line: "cl-orders -outp", wantLine: "cl-orders -output=", wantOk: true},
+		{name: "help argument test", line: "help refund", wantLine: "help refund-list ", wantOk: true},
+		{name: "read flag test", line: "read -o", wantLine: "read -output=", wantOk: true},
+		{name: "unknown command test", line: "nothing -", wantOk: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// act
+			line, pos, ok := complete(tt.line, len(tt.line), '\t')
+
+			// assert
+			assert.Equal(t, tt.wantOk, ok)
+			if tt.wantOk {
+				assert.Equal(t, tt.wantLine, line)
+				assert.Equal(t, len(tt.wantLine), pos)
+			}
+		})
+	}
+}
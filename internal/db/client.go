@@ -5,17 +5,112 @@ import (
 	"context"
 	"fmt"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func NewDb(ctx context.Context, dbCredentials *configuration.DBCredentials) (*Database, error) {
-	pool, err := pgxpool.Connect(ctx, generateDsn(dbCredentials))
+const maxConnectBackoff = 10 * time.Second
+
+// NewDb connects to PostgreSQL. While it is unavailable, the connection is retried
+// Pool.ConnectAttempts times with a delay doubling from Pool.ConnectBackoff. Queries are logged at the debug level
+func NewDb(ctx context.Context, dbCredentials *configuration.DBCredentials, logger *slog.Logger) (*Database, error) {
+	poolConfig, err := newPoolConfig(dbCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := max(dbCredentials.Pool.ConnectAttempts, 1)
+	backoff := dbCredentials.Pool.ConnectBackoff
+	for attempt := 1; ; attempt++ {
+		pool, err := connect(ctx, poolConfig)
+		if err == nil {
+			return newDataBase(pool, logger), nil
+		}
+		if attempt >= attempts {
+			if attempts > 1 {
+				return nil, fmt.Errorf("gave up after %d attempts: %w", attempts, err)
+			}
+			return nil, err
+		}
+
+		logger.Warn("cannot connect to PostgreSQL, retrying", "attempt", attempt, "delay", backoff, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
+
+// connect opens the pool and checks that the server answers
+func connect(ctx context.Context, poolConfig *pgxpool.Config) (*pgxpool.Pool, error) {
+	pool, err := pgxpool.ConnectConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, err
 	}
-	return newDataBase(pool), nil
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return pool, nil
+}
+
+func newPoolConfig(dbCredentials *configuration.DBCredentials) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(generateDsn(dbCredentials))
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection settings: %w", err)
+	}
+
+	pool := dbCredentials.Pool
+	if pool.MaxConns > 0 {
+		poolConfig.MaxConns = int32(pool.MaxConns)
+	}
+	if pool.MinConns > 0 {
+		poolConfig.MinConns = int32(pool.MinConns)
+	}
+	if pool.MaxConnLifetime > 0 {
+		poolConfig.MaxConnLifetime = pool.MaxConnLifetime
+	}
+	if pool.MaxConnIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = pool.MaxConnIdleTime
+	}
+	if pool.StatementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(pool.StatementTimeout.Milliseconds(), 10)
+	}
+	return poolConfig, nil
 }
 
 func generateDsn(dbCredentials *configuration.DBCredentials) string {
-	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		dbCredentials.Host, dbCredentials.Port, dbCredentials.User, dbCredentials.Password, dbCredentials.DBname)
+	sslMode := dbCredentials.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	settings := [][2]string{
+		{"host", dbCredentials.Host},
+		{"port", dbCredentials.Port},
+		{"user", dbCredentials.User},
+		{"password", dbCredentials.Password},
+		{"dbname", dbCredentials.DBname},
+		{"sslmode", sslMode},
+		{"sslrootcert", dbCredentials.SSLRootCert},
+		{"sslcert", dbCredentials.SSLCert},
+		{"sslkey", dbCredentials.SSLKey},
+	}
+
+	parts := make([]string, 0, len(settings))
+	for _, setting := range settings {
+		if setting[1] == "" && strings.HasPrefix(setting[0], "ssl") {
+			continue
+		}
+		parts = append(parts, setting[0]+"="+dsnValue(setting[1]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// dsnValue quotes the value, so that passwords and paths may contain spaces and quotes
+func dsnValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
 }
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"GOHW-1/internal/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func Test_generateDsn(t *testing.T) {
+	t.Parallel()
+	t.Run("smoke test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		dbCredentials := configuration.NewDBCredentials()
+		dbCredentials.SetCredits("localhost", "5432", "postgres", "it's a secret", "gohw")
+
+		// act
+		dsn := generateDsn(dbCredentials)
+
+		// assert
+		assert.Equal(t, `host='localhost' port='5432' user='postgres' password='it\'s a secret' dbname='gohw' sslmode='disable'`, dsn)
+	})
+	t.Run("tls test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		dbCredentials := configuration.NewDBCredentials()
+		dbCredentials.SetCredits("db", "5432", "postgres", "", "gohw")
+		dbCredentials.SSLMode, dbCredentials.SSLRootCert = "verify-full", "/etc/ssl/ca.pem"
+
+		// act
+		dsn := generateDsn(dbCredentials)
+
+		// assert
+		assert.Equal(t, `host='db' port='5432' user='postgres' password='' dbname='gohw' sslmode='verify-full' sslrootcert='/etc/ssl/ca.pem'`, dsn)
+	})
+}
+
+func Test_newPoolConfig(t *testing.T) {
+	t.Parallel()
+	t.Run("smoke test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		dbCredentials := &configuration.NewConfig().Postgres
+		dbCredentials.Pool.MaxConns = 20
+		dbCredentials.Pool.StatementTimeout = 3 * time.Second
+
+		// act
+		poolConfig, err := newPoolConfig(dbCredentials)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, int32(20), poolConfig.MaxConns)
+		assert.Equal(t, time.Hour, poolConfig.MaxConnLifetime)
+		assert.Equal(t, 30*time.Minute, poolConfig.MaxConnIdleTime)
+		assert.Equal(t, "3000", poolConfig.ConnConfig.RuntimeParams["statement_timeout"])
+		assert.Equal(t, "gohw", poolConfig.ConnConfig.Database)
+	})
+}
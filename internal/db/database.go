@@ -2,42 +2,120 @@ package db
 
 import (
 	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"GOHW-1/internal/tracing"
 
 	"github.com/georgysavva/scany/pgxscan"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Database struct {
 	cluster *pgxpool.Pool
+	logger  *slog.Logger
+}
+
+// querier runs queries in the pool or in a transaction
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
 }
 
-func newDataBase(cluster *pgxpool.Pool) *Database {
-	return &Database{cluster: cluster}
+func newDataBase(cluster *pgxpool.Pool, logger *slog.Logger) *Database {
+	return &Database{cluster: cluster, logger: logger}
 }
 func (db Database) GetPool(_ context.Context) *pgxpool.Pool {
 	return db.cluster
 }
 
+// querier returns the transaction carried by the context (see WithTx) or the pool
+func (db Database) querier(ctx context.Context) querier {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return db.cluster
+}
+
 func (db Database) Get(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
-	return pgxscan.Get(ctx, db.cluster, dest, query, args...)
+	ctx, finish := db.startQuery(ctx, query)
+	err := pgxscan.Get(ctx, db.querier(ctx), dest, query, args...)
+	finish(err)
+	return err
 }
 
 func (db Database) Select(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
-	return pgxscan.Select(ctx, db.cluster, dest, query, args...)
+	ctx, finish := db.startQuery(ctx, query)
+	err := pgxscan.Select(ctx, db.querier(ctx), dest, query, args...)
+	finish(err)
+	return err
 }
 
 func (db Database) Exec(ctx context.Context, query string, args ...interface{}) (pgconn.CommandTag, error) {
-	return db.cluster.Exec(ctx, query, args...)
+	ctx, finish := db.startQuery(ctx, query)
+	commandTag, err := db.querier(ctx).Exec(ctx, query, args...)
+	finish(err)
+	return commandTag, err
 }
 
+// ExecQueryRow runs the query, the row is scanned by the caller, so the logged duration and the span do not
+// include reading it
 func (db Database) ExecQueryRow(ctx context.Context, query string, args ...interface{}) pgx.Row {
-	return db.cluster.QueryRow(ctx, query, args...)
+	ctx, finish := db.startQuery(ctx, query)
+	row := db.querier(ctx).QueryRow(ctx, query, args...)
+	finish(nil)
+	return row
+}
+
+// startQuery starts the span of the query. The returned function ends the span and logs the query at the debug
+// level with the request ID of the context
+func (db Database) startQuery(ctx context.Context, query string) (context.Context, func(err error)) {
+	start := time.Now()
+	statement := strings.Join(strings.Fields(query), " ")
+	operation := "query"
+	if fields := strings.Fields(statement); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+	ctx, span := tracing.Tracer().Start(ctx, operation, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperation(operation), semconv.DBStatement(statement)))
+
+	return ctx, func(err error) {
+		// a missing row is an answer to the query rather than its failure
+		if err != nil && !pgxscan.NotFound(err) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		if db.logger == nil || !db.logger.Enabled(ctx, slog.LevelDebug) {
+			return
+		}
+		attrs := []slog.Attr{
+			slog.String("sql", statement),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.Any("error", err))
+		}
+		db.logger.LogAttrs(ctx, slog.LevelDebug, "query", attrs...)
+	}
+}
+
+// Ping checks that the server answers
+func (db Database) Ping(ctx context.Context) error {
+	return db.cluster.Ping(ctx)
 }
 
+// BeginTX starts a transaction that the caller commits or rolls back, prefer WithTx
 func (db Database) BeginTX(ctx context.Context) (pgx.Tx, error) {
-	return db.BeginTX(ctx)
+	return db.cluster.Begin(ctx)
 }
 
 func (db Database) RollbackTX(ctx context.Context, tx pgx.Tx) error {
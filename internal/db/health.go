@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Health keeps the result of the last database check for the readiness endpoint
+type Health struct {
+	database  pinger
+	interval  time.Duration
+	logger    *slog.Logger
+	err       error
+	checkedAt time.Time
+	mutex     sync.RWMutex
+}
+
+// NewHealth returns the health of a database that was just connected, so it is healthy until the first check
+func NewHealth(database pinger, interval time.Duration, logger *slog.Logger) *Health {
+	return &Health{database: database, interval: interval, logger: logger, checkedAt: time.Now()}
+}
+
+// Run checks the database every interval until the context is cancelled, a zero interval turns checks off
+func (health *Health) Run(ctx context.Context) {
+	if health.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(health.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			health.Check(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Check pings the database and stores the result, status changes are logged
+func (health *Health) Check(ctx context.Context) error {
+	timeout := health.interval
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := health.database.Ping(ctx)
+
+	health.mutex.Lock()
+	defer health.mutex.Unlock()
+	if err != nil && health.err == nil {
+		health.logger.Error("PostgreSQL health check failed", "error", err)
+	} else if err == nil && health.err != nil {
+		health.logger.Info("PostgreSQL is available again")
+	}
+	health.err, health.checkedAt = err, time.Now()
+	return err
+}
+
+// Status returns when the database was last checked and the error of the check, nil if the database answered
+func (health *Health) Status() (time.Time, error) {
+	health.mutex.RLock()
+	defer health.mutex.RUnlock()
+	return health.checkedAt, health.err
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"GOHW-1/internal/logger"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) Ping(ctx context.Context) error {
+	return f(ctx)
+}
+
+func TestHealth_Check(t *testing.T) {
+	t.Parallel()
+	t.Run("smoke test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		pingErr := errors.New("connection refused")
+		health := NewHealth(pingerFunc(func(ctx context.Context) error { return pingErr }), time.Second, logger.Discard())
+
+		// act
+		_, initialErr := health.Status()
+		checkErr := health.Check(context.Background())
+		_, failedErr := health.Status()
+		pingErr = nil
+		health.Check(context.Background())
+		_, recoveredErr := health.Status()
+
+		// assert
+		assert.NoError(t, initialErr)
+		require.Error(t, checkErr)
+		assert.Equal(t, "connection refused", failedErr.Error())
+		assert.NoError(t, recoveredErr)
+	})
+	t.Run("timeout test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		health := NewHealth(pingerFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}), 10*time.Millisecond, logger.Discard())
+
+		// act
+		err := health.Check(context.Background())
+
+		// assert
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
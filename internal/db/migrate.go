@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"github.com/jackc/pgx/v4/stdlib"
+	"github.com/pressly/goose/v3"
+	"io"
+	"log"
+	"slices"
+)
+
+const migrationsDir = "migrations"
+
+// migrationLockID is the key of the advisory lock held while migrating, any constant shared by all instances works
+const migrationLockID int64 = 0x676f6877 // "gohw"
+
+// MigrateCommands are goose commands accepted by Migrate
+var MigrateCommands = []string{"up", "down", "status", "redo"}
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrate runs a goose command with migrations embedded in the binary and writes its output to out.
+// An advisory lock is held while it runs, so instances started together migrate one after another
+func (db Database) Migrate(ctx context.Context, command string, out io.Writer) error {
+	if !slices.Contains(MigrateCommands, command) {
+		return fmt.Errorf("unknown migrate command %q: expected up, down, status or redo", command)
+	}
+
+	// migrations may run longer than the statement timeout of the pool
+	connConfig := db.cluster.Config().ConnConfig.Copy()
+	delete(connConfig.RuntimeParams, "statement_timeout")
+	sqlDB := stdlib.OpenDB(*connConfig)
+	defer sqlDB.Close()
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", migrationLockID).Scan(&locked); err != nil {
+		return fmt.Errorf("cannot take the migration lock: %w", err)
+	}
+	if !locked {
+		db.logger.Info("waiting for another instance to finish migrations")
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+			return fmt.Errorf("cannot take the migration lock: %w", err)
+		}
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	goose.SetBaseFS(migrations)
+	goose.SetLogger(log.New(out, "", 0))
+	if err := goose.SetDialect("postgres"); err != nil {
+		return err
+	}
+	switch command {
+	case "up":
+		return goose.UpContext(ctx, sqlDB, migrationsDir)
+	case "down":
+		return goose.DownContext(ctx, sqlDB, migrationsDir)
+	case "status":
+		return goose.StatusContext(ctx, sqlDB, migrationsDir)
+	default:
+		return goose.RedoContext(ctx, sqlDB, migrationsDir)
+	}
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_migrations(t *testing.T) {
+	t.Parallel()
+	t.Run("embedded test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		files, err := filepath.Glob(filepath.Join(migrationsDir, "*.sql"))
+		require.NoError(t, err)
+
+		// act
+		embedded, err := fs.Glob(migrations, migrationsDir+"/*.sql")
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, files, embedded)
+		for _, file := range embedded {
+			content, err := fs.ReadFile(migrations, file)
+			require.NoError(t, err)
+			onDisk, err := os.ReadFile(file)
+			require.NoError(t, err)
+			assert.Equal(t, string(onDisk), string(content))
+		}
+	})
+}
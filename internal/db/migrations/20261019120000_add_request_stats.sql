@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE request_stats
+(
+    id             BIGSERIAL PRIMARY KEY    NOT NULL,
+    route          TEXT                     NOT NULL,
+    window_seconds BIGINT                   NOT NULL,
+    count          BIGINT                   NOT NULL DEFAULT 0,
+    errors         BIGINT                   NOT NULL DEFAULT 0,
+    error_rate     DOUBLE PRECISION         NOT NULL DEFAULT 0,
+    p50_ms         DOUBLE PRECISION         NOT NULL DEFAULT 0,
+    p95_ms         DOUBLE PRECISION         NOT NULL DEFAULT 0,
+    p99_ms         DOUBLE PRECISION         NOT NULL DEFAULT 0,
+    computed_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+CREATE INDEX request_stats_route_computed_at_idx ON request_stats (route, computed_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table request_stats;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE orders
+(
+    id               BIGINT PRIMARY KEY       NOT NULL,
+    client_id        BIGINT                   NOT NULL,
+    expiration_date  TIMESTAMP WITH TIME ZONE NOT NULL,
+    weight           DOUBLE PRECISION         NOT NULL DEFAULT 0,
+    price            DOUBLE PRECISION         NOT NULL DEFAULT 0,
+    packaging        TEXT                     NOT NULL DEFAULT '',
+    pick_up_point_id BIGINT                   NOT NULL DEFAULT 0,
+    state            TEXT                     NOT NULL CHECK (state IN ('available', 'given', 'refunded')),
+    given_time       TIMESTAMP WITH TIME ZONE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table orders;
+-- +goose StatementEnd
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"time"
+)
+
+const (
+	defaultTxRetries = 3
+	txRetryDelay     = 10 * time.Millisecond
+)
+
+type txKey struct{}
+
+type txConfig struct {
+	options pgx.TxOptions
+	retries int
+}
+
+// TxOption changes how WithTx starts a transaction
+type TxOption func(config *txConfig)
+
+// WithIsolation sets the isolation level, e.g. pgx.Serializable (read committed by default)
+func WithIsolation(level pgx.TxIsoLevel) TxOption {
+	return func(config *txConfig) {
+		config.options.IsoLevel = level
+	}
+}
+
+// ReadOnly starts a read-only transaction
+func ReadOnly() TxOption {
+	return func(config *txConfig) {
+		config.options.AccessMode = pgx.ReadOnly
+	}
+}
+
+// WithRetries sets how many times a transaction is run again after a serialization failure or a deadlock
+func WithRetries(retries int) TxOption {
+	return func(config *txConfig) {
+		config.retries = retries
+	}
+}
+
+// WithTx runs fn in a transaction carried by the context passed to it: Database methods called with that context
+// run inside the transaction, so repositories do not need to know about it. The transaction is committed if fn
+// returns nil and rolled back otherwise or on panic.
+//
+// Called inside another transaction, WithTx starts a savepoint instead: an error rolls back only the work of fn,
+// options are taken from the outer transaction. A top-level transaction that fails to serialize or deadlocks
+// is run again from the start, so fn must not have side effects outside the database
+func (db Database) WithTx(ctx context.Context, fn func(ctx context.Context) error, options ...TxOption) error {
+	if outer, ok := TxFromContext(ctx); ok {
+		return runTx(ctx, outer.Begin, fn)
+	}
+
+	config := txConfig{retries: defaultTxRetries}
+	for _, option := range options {
+		option(&config)
+	}
+	begin := func(ctx context.Context) (pgx.Tx, error) {
+		return db.cluster.BeginTx(ctx, config.options)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := runTx(ctx, begin, fn)
+		if err == nil || attempt >= config.retries || !isRetryable(err) {
+			return err
+		}
+		select {
+		case <-time.After(time.Duration(attempt+1) * txRetryDelay):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+func runTx(ctx context.Context, begin func(ctx context.Context) (pgx.Tx, error), fn func(ctx context.Context) error) (err error) {
+	tx, err := begin(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot begin transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			return errors.Join(err, fmt.Errorf("cannot roll back transaction: %w", rollbackErr))
+		}
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// TxFromContext returns the transaction started by WithTx, if any
+func TxFromContext(ctx context.Context) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
+	return tx, ok
+}
+
+// isRetryable reports whether the transaction failed because of concurrent transactions and may succeed if run again
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	// serialization_failure and deadlock_detected
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_isRetryable(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "serialization failure test", err: &pgconn.PgError{Code: "40001"}, want: true},
+		{name: "wrapped deadlock test", err: fmt.Errorf("cannot update: %w", &pgconn.PgError{Code: "40P01"}), want: true},
+		{name: "unique violation test", err: &pgconn.PgError{Code: "23505"}, want: false},
+		{name: "other error test", err: errors.New("connection refused"), want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// act
+			got := isRetryable(tt.err)
+
+			// assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTxFromContext(t *testing.T) {
+	t.Parallel()
+	t.Run("no transaction test", func(t *testing.T) {
+		t.Parallel()
+		// act
+		_, ok := TxFromContext(context.Background())
+
+		// assert
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,168 @@
+package grpcserver
+
+import (
+	"GOHW-1/internal/controller"
+	"GOHW-1/internal/logger"
+	"GOHW-1/internal/tracing"
+	"context"
+	"encoding/base64"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// loggingMethod replaces the HTTP method in logging messages of calls, so that analytics tell them apart from
+// HTTP requests
+const loggingMethod = "GRPC"
+
+// metadataCarrier carries trace context in metadata of a call, keys of metadata are lower case
+type metadataCarrier metadata.MD
+
+func (carrier metadataCarrier) Get(key string) string {
+	if values := metadata.MD(carrier).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (carrier metadataCarrier) Set(key string, value string) {
+	metadata.MD(carrier).Set(key, value)
+}
+
+func (carrier metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(carrier))
+	for key := range carrier {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// incoming returns metadata of the call, empty if there is none
+func incoming(ctx context.Context) metadata.MD {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return metadata.MD{}
+	}
+	return md
+}
+
+// RequestIDInterceptor is RequestIDMiddleware of calls: it reuses a valid x-request-id of the call or generates one,
+// returns it in the response header and puts it into the context
+func (server *Server) RequestIDInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (any, error) {
+	requestID := metadataCarrier(incoming(ctx)).Get(logger.RequestIDHeader)
+	if !logger.ValidRequestID(requestID) {
+		requestID = logger.NewRequestID()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(logger.RequestIDHeader, requestID))
+	return handler(logger.WithRequestID(ctx, requestID), req)
+}
+
+// TracingInterceptor is TracingMiddleware of calls: it continues the trace of W3C trace context in metadata
+// with a server span named after the method
+func (server *Server) TracingInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (any, error) {
+	ctx = tracing.Propagator.Extract(ctx, metadataCarrier(incoming(ctx)))
+	service, method, _ := strings.Cut(strings.TrimPrefix(info.FullMethod, "/"), "/")
+	ctx, span := tracing.Tracer().Start(ctx, info.FullMethod, trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(semconv.RPCSystemGRPC, semconv.RPCService(service), semconv.RPCMethod(method),
+			attribute.String("request_id", logger.RequestID(ctx))))
+	defer span.End()
+
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+	if httpStatus(code) >= http.StatusInternalServerError {
+		span.SetStatus(otelcodes.Error, code.String())
+	}
+	return resp, err
+}
+
+// AuthInterceptor is AuthMiddleware of calls: the authorization metadata must hold the basic auth credentials
+// of the http section
+func (server *Server) AuthInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (any, error) {
+	username, password, ok := basicAuth(metadataCarrier(incoming(ctx)).Get("authorization"))
+	if !ok || username != server.http.Username || password != server.http.Password {
+		return nil, status.Error(codes.Unauthenticated, "authentication failed")
+	}
+	return handler(ctx, req)
+}
+
+// basicAuth parses credentials of an authorization value, see http.Request.BasicAuth
+func basicAuth(authorization string) (string, string, bool) {
+	const prefix = "Basic "
+	if len(authorization) < len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(authorization[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(decoded), ":")
+}
+
+// LoggingInterceptor is LoggingMiddleware of calls: it logs calls with their code and duration and sends them
+// as logging messages with the HTTP status of the code. A failure to send the logging message does not fail the call
+func (server *Server) LoggingInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err)
+	message := controller.LoggingMessage{
+		Method:    loggingMethod,
+		URI:       info.FullMethod,
+		Route:     info.FullMethod,
+		Status:    httpStatus(code),
+		Duration:  time.Since(start),
+		Time:      start,
+		RequestID: logger.RequestID(ctx),
+	}
+	server.logger.InfoContext(ctx, "call", "method", info.FullMethod, "code", code.String(), "duration", message.Duration)
+	if err := server.sender.SendAsyncMessage(ctx, message); err != nil {
+		server.logger.WarnContext(ctx, "cannot send the logging message", "error", err)
+	}
+	return resp, err
+}
+
+// httpStatus returns the HTTP status matching the code, so that analytics count failed calls like failed requests
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
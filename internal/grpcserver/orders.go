@@ -0,0 +1,119 @@
+package grpcserver
+
+import (
+	"GOHW-1/internal/controller"
+	"GOHW-1/internal/model"
+	"GOHW-1/internal/pb"
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strconv"
+)
+
+func toOrders(orders []model.Order) []*pb.Order {
+	result := make([]*pb.Order, 0, len(orders))
+	for _, order := range orders {
+		result = append(result, toOrder(order))
+	}
+	return result
+}
+
+func toOrder(order model.Order) *pb.Order {
+	return &pb.Order{
+		Id:             int64(order.ID),
+		ClientId:       int64(order.ClientID),
+		ExpirationDate: timestamppb.New(order.ExpirationDate),
+		Weight:         order.Weight,
+		Price:          order.Price,
+		Packaging:      order.Packaging,
+		PickUpPointId:  order.PickUpPointID,
+	}
+}
+
+// orderError returns the status of a failed order operation. Failures to read or write the storage are internal,
+// the storage rejects operations that break business rules with plain errors, which are failed preconditions
+func (server *Server) orderError(ctx context.Context, err error) error {
+	var argumentError *controller.ArgumentError
+	if errors.As(err, &argumentError) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if errors.Is(err, model.ErrStorage) {
+		server.logger.ErrorContext(ctx, "cannot access the order storage", "error", err)
+		return status.Errorf(codes.Internal, "cannot access the order storage: %v", err)
+	}
+	return status.Error(codes.FailedPrecondition, err.Error())
+}
+
+// TakeOrder accepts an order from the courier
+func (server *Server) TakeOrder(ctx context.Context, req *pb.TakeOrderRequest) (*pb.TakeOrderResponse, error) {
+	server.ordersMutex.Lock()
+	defer server.ordersMutex.Unlock()
+	order, err := server.orders.TakeOrder(int(req.GetOrderId()), int(req.GetClientId()), req.GetAvailableTime().AsDuration(),
+		req.GetWeight(), req.GetPrice(), req.GetPackaging(), req.GetPickUpPointId())
+	if err != nil {
+		return nil, server.orderError(ctx, err)
+	}
+	return &pb.TakeOrderResponse{Order: toOrder(order)}, nil
+}
+
+// ReturnOrder returns an expired order to the courier
+func (server *Server) ReturnOrder(ctx context.Context, req *pb.ReturnOrderRequest) (*pb.ReturnOrderResponse, error) {
+	server.ordersMutex.Lock()
+	defer server.ordersMutex.Unlock()
+	if err := server.orders.ReturnOrder(int(req.GetOrderId())); err != nil {
+		return nil, server.orderError(ctx, err)
+	}
+	return &pb.ReturnOrderResponse{}, nil
+}
+
+// GiveOrders gives orders to the client
+func (server *Server) GiveOrders(ctx context.Context, req *pb.GiveOrdersRequest) (*pb.GiveOrdersResponse, error) {
+	server.ordersMutex.Lock()
+	defer server.ordersMutex.Unlock()
+	ordersID := make([]string, 0, len(req.GetOrderIds()))
+	for _, orderID := range req.GetOrderIds() {
+		ordersID = append(ordersID, strconv.FormatInt(orderID, 10))
+	}
+	if err := server.orders.GiveOrders(int(req.GetClientId()), ordersID); err != nil {
+		return nil, server.orderError(ctx, err)
+	}
+	return &pb.GiveOrdersResponse{}, nil
+}
+
+// ListClientOrders returns last orders of the client
+func (server *Server) ListClientOrders(ctx context.Context, req *pb.ListClientOrdersRequest) (*pb.ListClientOrdersResponse, error) {
+	server.ordersMutex.Lock()
+	defer server.ordersMutex.Unlock()
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = -1
+	}
+	orders, err := server.orders.ClientOrders(int(req.GetClientId()), limit, req.GetOnlyUserOrders())
+	if err != nil {
+		return nil, server.orderError(ctx, err)
+	}
+	return &pb.ListClientOrdersResponse{Orders: toOrders(orders)}, nil
+}
+
+// RefundOrder accepts a return of the order from the client
+func (server *Server) RefundOrder(ctx context.Context, req *pb.RefundOrderRequest) (*pb.RefundOrderResponse, error) {
+	server.ordersMutex.Lock()
+	defer server.ordersMutex.Unlock()
+	if err := server.orders.RefundOrder(int(req.GetOrderId()), int(req.GetClientId())); err != nil {
+		return nil, server.orderError(ctx, err)
+	}
+	return &pb.RefundOrderResponse{}, nil
+}
+
+// ListRefunds returns a page of refunded orders
+func (server *Server) ListRefunds(ctx context.Context, req *pb.ListRefundsRequest) (*pb.ListRefundsResponse, error) {
+	server.ordersMutex.Lock()
+	defer server.ordersMutex.Unlock()
+	orders, err := server.orders.RefundList(int(req.GetPage()))
+	if err != nil {
+		return nil, server.orderError(ctx, err)
+	}
+	return &pb.ListRefundsResponse{Orders: toOrders(orders)}, nil
+}
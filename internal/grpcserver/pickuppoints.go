@@ -0,0 +1,79 @@
+package grpcserver
+
+import (
+	"GOHW-1/internal/model"
+	"GOHW-1/internal/pb"
+	"context"
+	"database/sql"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func toPickUpPoint(pickUpPoint model.PickUpPoint) *pb.PickUpPoint {
+	return &pb.PickUpPoint{
+		Id:      pickUpPoint.ID,
+		Name:    pickUpPoint.Name,
+		Address: pickUpPoint.Address,
+		Contact: pickUpPoint.Contact,
+	}
+}
+
+// repoError returns the status of a failed repository operation on the pick-up point with the ID
+func (server *Server) repoError(ctx context.Context, operation string, id int64, err error) error {
+	if errors.Is(err, model.ErrObjectNotFound) || errors.Is(err, sql.ErrNoRows) {
+		return status.Error(codes.NotFound, "pick-up point not found")
+	}
+	server.logger.ErrorContext(ctx, "cannot "+operation+" pick-up point", "id", id, "error", err)
+	return status.Errorf(codes.Internal, "cannot %s pick-up point: %v", operation, err)
+}
+
+// CreatePickUpPoint creates a pick-up point and returns it with its ID
+func (server *Server) CreatePickUpPoint(ctx context.Context, req *pb.CreatePickUpPointRequest) (*pb.PickUpPoint, error) {
+	pickUpPoint := model.PickUpPoint{Name: req.GetName(), Address: req.GetAddress(), Contact: req.GetContact()}
+	id, err := server.repo.Create(ctx, &pickUpPoint)
+	if err != nil {
+		return nil, server.repoError(ctx, "create", 0, err)
+	}
+	pickUpPoint.ID = id
+	return toPickUpPoint(pickUpPoint), nil
+}
+
+// GetPickUpPoint returns the pick-up point with the ID
+func (server *Server) GetPickUpPoint(ctx context.Context, req *pb.GetPickUpPointRequest) (*pb.PickUpPoint, error) {
+	pickUpPoint, err := server.repo.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, server.repoError(ctx, "get", req.GetId(), err)
+	}
+	return toPickUpPoint(*pickUpPoint), nil
+}
+
+// ListPickUpPoints returns all pick-up points
+func (server *Server) ListPickUpPoints(ctx context.Context, _ *pb.ListPickUpPointsRequest) (*pb.ListPickUpPointsResponse, error) {
+	pickUpPoints, err := server.repo.List(ctx)
+	if err != nil {
+		return nil, server.repoError(ctx, "list", 0, err)
+	}
+	resp := &pb.ListPickUpPointsResponse{PickUpPoints: make([]*pb.PickUpPoint, 0, len(pickUpPoints))}
+	for _, pickUpPoint := range pickUpPoints {
+		resp.PickUpPoints = append(resp.PickUpPoints, toPickUpPoint(pickUpPoint))
+	}
+	return resp, nil
+}
+
+// UpdatePickUpPoint replaces the name, address and contact of the pick-up point with the ID
+func (server *Server) UpdatePickUpPoint(ctx context.Context, req *pb.UpdatePickUpPointRequest) (*pb.PickUpPoint, error) {
+	pickUpPoint := model.PickUpPoint{ID: req.GetId(), Name: req.GetName(), Address: req.GetAddress(), Contact: req.GetContact()}
+	if err := server.repo.Update(ctx, req.GetId(), pickUpPoint); err != nil {
+		return nil, server.repoError(ctx, "update", req.GetId(), err)
+	}
+	return toPickUpPoint(pickUpPoint), nil
+}
+
+// DeletePickUpPoint deletes the pick-up point with the ID
+func (server *Server) DeletePickUpPoint(ctx context.Context, req *pb.DeletePickUpPointRequest) (*pb.DeletePickUpPointResponse, error) {
+	if err := server.repo.Delete(ctx, req.GetId()); err != nil {
+		return nil, server.repoError(ctx, "delete", req.GetId(), err)
+	}
+	return &pb.DeletePickUpPointResponse{}, nil
+}
@@ -0,0 +1,45 @@
+package grpcserver
+
+import (
+	"GOHW-1/internal/configuration"
+	"GOHW-1/internal/controller"
+	"GOHW-1/internal/pb"
+	"google.golang.org/grpc"
+	"log/slog"
+	"sync"
+)
+
+// Server implements the gRPC API of api/gohw.proto over the same repository, order controller and events sink
+// as the HTTP API
+type Server struct {
+	pb.UnimplementedPickUpPointsServer
+	pb.UnimplementedOrdersServer
+
+	repo   controller.PickUpPointsRepo
+	orders *controller.OrderController
+	sender controller.Sender
+	http   configuration.HTTPConfig
+	logger *slog.Logger
+	// ordersMutex serializes order operations, the JSON storage reads and writes its files without locking them
+	ordersMutex sync.Mutex
+}
+
+// New returns the server of the API, calls are authenticated with credentials of httpConfig
+func New(repo controller.PickUpPointsRepo, orders *controller.OrderController, sender controller.Sender,
+	httpConfig configuration.HTTPConfig, logger *slog.Logger) *Server {
+	return &Server{repo: repo, orders: orders, sender: sender, http: httpConfig, logger: logger}
+}
+
+// Register returns a gRPC server with both services and interceptors in the order of the HTTP middlewares
+func (server *Server) Register(options ...grpc.ServerOption) *grpc.Server {
+	options = append(options, grpc.ChainUnaryInterceptor(
+		server.RequestIDInterceptor,
+		server.TracingInterceptor,
+		server.AuthInterceptor,
+		server.LoggingInterceptor,
+	))
+	grpcServer := grpc.NewServer(options...)
+	pb.RegisterPickUpPointsServer(grpcServer, server)
+	pb.RegisterOrdersServer(grpcServer, server)
+	return grpcServer
+}
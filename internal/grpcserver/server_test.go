@@ -0,0 +1,267 @@
+package grpcserver
+
+import (
+	"GOHW-1/internal/clock"
+	"GOHW-1/internal/configuration"
+	"GOHW-1/internal/controller"
+	mock_controller "GOHW-1/internal/controller/mocks"
+	"GOHW-1/internal/logger"
+	"GOHW-1/internal/model"
+	"GOHW-1/internal/pb"
+	"GOHW-1/internal/service"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	httpConfig = *configuration.NewHTTPConfig()
+	now        = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+)
+
+// memoryStorage keeps orders taken from couriers in memory, reading them fails with readErr if it is set
+type memoryStorage struct {
+	orders  []model.Order
+	readErr error
+	mutex   sync.Mutex
+}
+
+func (s *memoryStorage) CourierTakeOrder(order model.Order) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, taken := range s.orders {
+		if taken.ID == order.ID {
+			return errors.New("order has been already accepted")
+		}
+	}
+	s.orders = append(s.orders, order)
+	return nil
+}
+
+func (s *memoryStorage) CourierGiveOrder(int) error                     { return nil }
+func (s *memoryStorage) ClientGiveOrder(int, []string) error            { return nil }
+func (s *memoryStorage) ClientRefund(int, int) error                    { return nil }
+func (s *memoryStorage) RefundList(int) ([]model.Order, error)          { return nil, nil }
+func (s *memoryStorage) PickUpPointWrite(model.PickUpPoint) error       { return nil }
+func (s *memoryStorage) PickUpPointsRead() ([]model.PickUpPoint, error) { return nil, nil }
+
+func (s *memoryStorage) ClientGetOrders(clientID int, _ int, _ bool) ([]model.Order, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.readErr != nil {
+		return nil, s.readErr
+	}
+	var orders []model.Order
+	for _, order := range s.orders {
+		if order.ClientID == clientID {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+type fixture struct {
+	repo   *mock_controller.MockPickUpPointsRepo
+	sender *controller.MemorySender
+	conn   *grpc.ClientConn
+}
+
+// serve runs the server on an in-memory listener and returns a connection to it
+func serve(t *testing.T) fixture {
+	t.Helper()
+	return serveStorage(t, &memoryStorage{})
+}
+
+// serveStorage runs the server with orders of the storage
+func serveStorage(t *testing.T, storage *memoryStorage) fixture {
+	t.Helper()
+	repo := mock_controller.NewMockPickUpPointsRepo(gomock.NewController(t))
+	sender := controller.NewMemorySender()
+	svc := service.New(storage)
+	orders := controller.NewOrderController(&svc, configuration.NewRuntime(*configuration.NewRuntimeConfig()),
+		clock.NewFake(now), slog.Default(), &sync.WaitGroup{}, context.Background())
+	server := New(repo, orders, sender, httpConfig, logger.Discard()).Register()
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return fixture{repo: repo, sender: sender, conn: conn}
+}
+
+func authenticated(username string, password string) context.Context {
+	credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Basic "+credentials)
+}
+
+func Test_PickUpPoints(t *testing.T) {
+	t.Parallel()
+	t.Run("smoke test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		s := serve(t)
+		s.repo.EXPECT().GetByID(gomock.Any(), int64(1)).
+			Return(&model.PickUpPoint{ID: 1, Name: "A", Address: "Main St", Contact: "8-800"}, nil)
+		var header metadata.MD
+
+		// act
+		pickUpPoint, err := pb.NewPickUpPointsClient(s.conn).GetPickUpPoint(authenticated(httpConfig.Username, httpConfig.Password),
+			&pb.GetPickUpPointRequest{Id: 1}, grpc.Header(&header))
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, "A", pickUpPoint.GetName())
+		assert.Equal(t, "Main St", pickUpPoint.GetAddress())
+		assert.True(t, logger.ValidRequestID(header.Get(logger.RequestIDHeader)[0]))
+		messages := s.sender.Messages()
+		require.Len(t, messages, 1)
+		assert.Equal(t, "GRPC", messages[0].Method)
+		assert.Equal(t, pb.PickUpPoints_GetPickUpPoint_FullMethodName, messages[0].Route)
+		assert.Equal(t, http.StatusOK, messages[0].Status)
+	})
+	t.Run("not found test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		s := serve(t)
+		s.repo.EXPECT().Delete(gomock.Any(), int64(9999)).Return(model.ErrObjectNotFound)
+
+		// act
+		_, err := pb.NewPickUpPointsClient(s.conn).DeletePickUpPoint(authenticated(httpConfig.Username, httpConfig.Password),
+			&pb.DeletePickUpPointRequest{Id: 9999})
+
+		// assert
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		messages := s.sender.Messages()
+		require.Len(t, messages, 1)
+		assert.Equal(t, http.StatusNotFound, messages[0].Status)
+	})
+	t.Run("failed authentication test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		s := serve(t)
+
+		// act
+		_, err := pb.NewPickUpPointsClient(s.conn).ListPickUpPoints(authenticated("maksim", "makarov"),
+			&pb.ListPickUpPointsRequest{})
+
+		// assert
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Empty(t, s.sender.Messages())
+	})
+	t.Run("no credentials test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		s := serve(t)
+
+		// act
+		_, err := pb.NewPickUpPointsClient(s.conn).ListPickUpPoints(context.Background(), &pb.ListPickUpPointsRequest{})
+
+		// assert
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func Test_Orders(t *testing.T) {
+	t.Parallel()
+	t.Run("take and list test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		s := serve(t)
+		client := pb.NewOrdersClient(s.conn)
+		ctx := authenticated(httpConfig.Username, httpConfig.Password)
+
+		// act
+		taken, takeErr := client.TakeOrder(ctx, &pb.TakeOrderRequest{OrderId: 1, ClientId: 2,
+			AvailableTime: durationpb.New(48 * time.Hour), Weight: 1, Price: 100, Packaging: model.Carton})
+		orders, listErr := client.ListClientOrders(ctx, &pb.ListClientOrdersRequest{ClientId: 2})
+
+		// assert
+		require.NoError(t, takeErr)
+		require.NoError(t, listErr)
+		assert.Equal(t, 120.0, taken.GetOrder().GetPrice())
+		assert.Equal(t, now.Add(48*time.Hour), taken.GetOrder().GetExpirationDate().AsTime())
+		require.Len(t, orders.GetOrders(), 1)
+		assert.Equal(t, int64(1), orders.GetOrders()[0].GetId())
+	})
+	t.Run("errors test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		s := serve(t)
+		client := pb.NewOrdersClient(s.conn)
+		ctx := authenticated(httpConfig.Username, httpConfig.Password)
+		request := &pb.TakeOrderRequest{OrderId: 1, ClientId: 2, AvailableTime: durationpb.New(time.Hour), Packaging: model.Film}
+		_, err := client.TakeOrder(ctx, request)
+		require.NoError(t, err)
+
+		// act
+		_, invalidErr := client.TakeOrder(ctx, &pb.TakeOrderRequest{OrderId: 1, ClientId: 2, Packaging: model.Film})
+		_, twiceErr := client.TakeOrder(ctx, request)
+
+		// assert
+		assert.Equal(t, codes.InvalidArgument, status.Code(invalidErr))
+		assert.Equal(t, "available time is not given or incorrect", status.Convert(invalidErr).Message())
+		assert.Equal(t, codes.FailedPrecondition, status.Code(twiceErr))
+	})
+	t.Run("storage failure test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		s := serveStorage(t, &memoryStorage{readErr: fmt.Errorf("%w: unexpected end of JSON input", model.ErrStorage)})
+
+		// act
+		_, err := pb.NewOrdersClient(s.conn).ListClientOrders(authenticated(httpConfig.Username, httpConfig.Password),
+			&pb.ListClientOrdersRequest{ClientId: 2})
+
+		// assert
+		assert.Equal(t, codes.Internal, status.Code(err))
+		messages := s.sender.Messages()
+		require.Len(t, messages, 1)
+		assert.Equal(t, http.StatusInternalServerError, messages[0].Status)
+	})
+}
+
+func Test_httpStatus(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		code codes.Code
+		want int
+	}{
+		{codes.OK, http.StatusOK},
+		{codes.InvalidArgument, http.StatusBadRequest},
+		{codes.NotFound, http.StatusNotFound},
+		{codes.Unauthenticated, http.StatusUnauthorized},
+		{codes.Internal, http.StatusInternalServerError},
+		{codes.Unknown, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.code.String()+" test", func(t *testing.T) {
+			t.Parallel()
+			// act
+			got := httpStatus(tt.code)
+
+			// assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
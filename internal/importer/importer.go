@@ -0,0 +1,299 @@
+package importer
+
+import (
+	"GOHW-1/internal/db"
+	"GOHW-1/internal/model"
+	"GOHW-1/internal/output"
+	"GOHW-1/internal/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var errDryRun = errors.New("dry run")
+
+type source interface {
+	AvailableOrders() ([]storage.OrderDTO, error)
+	RefundedOrders() ([]storage.OrderDTO, error)
+	PickUpPointsRead() ([]model.PickUpPoint, error)
+}
+
+type transactor interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error, options ...db.TxOption) error
+}
+
+type orderRepo interface {
+	Upsert(ctx context.Context, order model.StoredOrder) error
+	List(ctx context.Context) ([]model.StoredOrder, error)
+}
+
+type pickUpPointRepo interface {
+	Upsert(ctx context.Context, pickUpPoint model.PickUpPoint) error
+	List(ctx context.Context) ([]model.PickUpPoint, error)
+	SyncIDSequence(ctx context.Context) error
+}
+
+// Importer copies orders and pick-up points from the JSON storage to PostgreSQL. Records are upserted by ID,
+// so running it again updates the database to the current files instead of duplicating them
+type Importer struct {
+	source       source
+	transactor   transactor
+	orders       orderRepo
+	pickUpPoints pickUpPointRepo
+}
+
+func New(source source, transactor transactor, orders orderRepo, pickUpPoints pickUpPointRepo) *Importer {
+	return &Importer{source: source, transactor: transactor, orders: orders, pickUpPoints: pickUpPoints}
+}
+
+// Count is the number of records of one kind in the files and in the database after the import
+type Count struct {
+	Entity   string `json:"entity"`
+	File     int    `json:"file"`
+	Database int    `json:"database"`
+}
+
+// Report reconciles the files with the database. Mismatches are records that differ after the import,
+// notes are expected differences: duplicates in the files and records that exist only in the database
+type Report struct {
+	Counts     []Count  `json:"counts"`
+	Mismatches []string `json:"mismatches"`
+	Notes      []string `json:"notes"`
+	DryRun     bool     `json:"dry_run"`
+}
+
+var countColumns = []output.Column[Count]{
+	{Name: "entity", Value: func(count Count) any { return count.Entity }},
+	{Name: "file", Value: func(count Count) any { return count.File }},
+	{Name: "database", Value: func(count Count) any { return count.Database }},
+}
+
+// Run imports all records in one transaction and reconciles them. With dryRun the transaction is rolled back,
+// so the report shows what the import would do
+func (importer *Importer) Run(ctx context.Context, dryRun bool) (*Report, error) {
+	pickUpPoints, orders, notes, err := importer.read()
+	if err != nil {
+		return nil, err
+	}
+
+	var report *Report
+	err = importer.transactor.WithTx(ctx, func(ctx context.Context) error {
+		for _, pickUpPoint := range pickUpPoints {
+			if err := importer.pickUpPoints.Upsert(ctx, pickUpPoint); err != nil {
+				return fmt.Errorf("cannot import pick-up point %d: %w", pickUpPoint.ID, err)
+			}
+		}
+		if err := importer.pickUpPoints.SyncIDSequence(ctx); err != nil {
+			return fmt.Errorf("cannot update pick-up point IDs: %w", err)
+		}
+		for _, order := range orders {
+			if err := importer.orders.Upsert(ctx, order); err != nil {
+				return fmt.Errorf("cannot import order %d: %w", order.ID, err)
+			}
+		}
+
+		var err error
+		if report, err = importer.reconcile(ctx, pickUpPoints, orders); err != nil {
+			return err
+		}
+		report.Notes = append(notes, report.Notes...)
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		report.DryRun = true
+		return report, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// read returns records of the files by ID. An order found in both files is imported from available orders,
+// the current state of the order, because a refunded order can be accepted again
+func (importer *Importer) read() ([]model.PickUpPoint, []model.StoredOrder, []string, error) {
+	var notes []string
+	filePickUpPoints, err := importer.source.PickUpPointsRead()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot read pick-up points: %w", err)
+	}
+	pickUpPoints := make([]model.PickUpPoint, 0, len(filePickUpPoints))
+	pickUpPointIndex := make(map[int64]int)
+	for _, pickUpPoint := range filePickUpPoints {
+		if i, ok := pickUpPointIndex[pickUpPoint.ID]; ok {
+			notes = append(notes, fmt.Sprintf("pick-up point %d is duplicated in the file, the last one is imported", pickUpPoint.ID))
+			pickUpPoints[i] = pickUpPoint
+			continue
+		}
+		pickUpPointIndex[pickUpPoint.ID] = len(pickUpPoints)
+		pickUpPoints = append(pickUpPoints, pickUpPoint)
+	}
+
+	availableOrders, err := importer.source.AvailableOrders()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot read available orders: %w", err)
+	}
+	refundedOrders, err := importer.source.RefundedOrders()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot read refunded orders: %w", err)
+	}
+	orders := make([]model.StoredOrder, 0, len(availableOrders)+len(refundedOrders))
+	orderIndex := make(map[int]int)
+	for _, order := range refundedOrders {
+		if i, ok := orderIndex[order.ID]; ok {
+			notes = append(notes, fmt.Sprintf("order %d is refunded twice, the last refund is imported", order.ID))
+			orders[i] = order.StoredOrder(model.OrderRefunded)
+			continue
+		}
+		orderIndex[order.ID] = len(orders)
+		orders = append(orders, order.StoredOrder(model.OrderRefunded))
+	}
+	for _, order := range availableOrders {
+		state := model.OrderAvailable
+		if order.IsGiven {
+			state = model.OrderGiven
+		}
+		if i, ok := orderIndex[order.ID]; ok {
+			notes = append(notes, fmt.Sprintf("order %d is in both files, it is imported as %s", order.ID, state))
+			orders[i] = order.StoredOrder(state)
+			continue
+		}
+		orderIndex[order.ID] = len(orders)
+		orders = append(orders, order.StoredOrder(state))
+	}
+	return pickUpPoints, orders, notes, nil
+}
+
+// reconcile compares imported records with the database
+func (importer *Importer) reconcile(ctx context.Context, pickUpPoints []model.PickUpPoint, orders []model.StoredOrder) (*Report, error) {
+	report := &Report{}
+
+	databasePickUpPoints, err := importer.pickUpPoints.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list pick-up points: %w", err)
+	}
+	pickUpPointsByID := make(map[int64]model.PickUpPoint, len(databasePickUpPoints))
+	for _, pickUpPoint := range databasePickUpPoints {
+		pickUpPointsByID[pickUpPoint.ID] = pickUpPoint
+	}
+	for _, pickUpPoint := range pickUpPoints {
+		databasePickUpPoint, ok := pickUpPointsByID[pickUpPoint.ID]
+		switch {
+		case !ok:
+			report.Mismatches = append(report.Mismatches, fmt.Sprintf("pick-up point %d is missing in the database", pickUpPoint.ID))
+		case databasePickUpPoint != pickUpPoint:
+			report.Mismatches = append(report.Mismatches, fmt.Sprintf("pick-up point %d differs: file %+v, database %+v",
+				pickUpPoint.ID, pickUpPoint, databasePickUpPoint))
+		}
+		delete(pickUpPointsByID, pickUpPoint.ID)
+	}
+	for _, pickUpPoint := range databasePickUpPoints {
+		if _, ok := pickUpPointsByID[pickUpPoint.ID]; ok {
+			report.Notes = append(report.Notes, fmt.Sprintf("pick-up point %d is only in the database", pickUpPoint.ID))
+		}
+	}
+
+	databaseOrders, err := importer.orders.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list orders: %w", err)
+	}
+	ordersByID := make(map[int]model.StoredOrder, len(databaseOrders))
+	for _, order := range databaseOrders {
+		ordersByID[order.ID] = order
+	}
+	for _, order := range orders {
+		databaseOrder, ok := ordersByID[order.ID]
+		switch {
+		case !ok:
+			report.Mismatches = append(report.Mismatches, fmt.Sprintf("order %d is missing in the database", order.ID))
+		case !sameOrder(order, databaseOrder):
+			report.Mismatches = append(report.Mismatches, fmt.Sprintf("order %d differs: file %s, database %s",
+				order.ID, describeOrder(order), describeOrder(databaseOrder)))
+		}
+		delete(ordersByID, order.ID)
+	}
+	for _, order := range databaseOrders {
+		if _, ok := ordersByID[order.ID]; ok {
+			report.Notes = append(report.Notes, fmt.Sprintf("order %d is only in the database", order.ID))
+		}
+	}
+
+	report.Counts = append(report.Counts, Count{Entity: "pick-up points", File: len(pickUpPoints), Database: len(databasePickUpPoints)})
+	for _, state := range []string{model.OrderAvailable, model.OrderGiven, model.OrderRefunded} {
+		report.Counts = append(report.Counts, Count{
+			Entity:   state + " orders",
+			File:     countState(orders, state),
+			Database: countState(databaseOrders, state),
+		})
+	}
+	return report, nil
+}
+
+func countState(orders []model.StoredOrder, state string) int {
+	count := 0
+	for _, order := range orders {
+		if order.State == state {
+			count++
+		}
+	}
+	return count
+}
+
+// sameOrder compares orders with the precision of PostgreSQL timestamps
+func sameOrder(a model.StoredOrder, b model.StoredOrder) bool {
+	sameTime := func(a time.Time, b time.Time) bool {
+		return a.Truncate(time.Microsecond).Equal(b.Truncate(time.Microsecond))
+	}
+	sameGivenTime := a.GivenTime == nil && b.GivenTime == nil ||
+		a.GivenTime != nil && b.GivenTime != nil && sameTime(*a.GivenTime, *b.GivenTime)
+	return a.ID == b.ID && a.ClientID == b.ClientID && sameTime(a.ExpirationDate, b.ExpirationDate) &&
+		a.Weight == b.Weight && a.Price == b.Price && a.Packaging == b.Packaging &&
+		a.PickUpPointID == b.PickUpPointID && a.State == b.State && sameGivenTime
+}
+
+func describeOrder(order model.StoredOrder) string {
+	givenTime := "-"
+	if order.GivenTime != nil {
+		givenTime = order.GivenTime.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("{client %d, expires %s, weight %v, price %v, %s, point %d, %s, given %s}", order.ClientID,
+		order.ExpirationDate.Format(time.RFC3339Nano), order.Weight, order.Price, order.Packaging, order.PickUpPointID,
+		order.State, givenTime)
+}
+
+// Print writes the report in the given output format. JSON formats print the whole report as one object,
+// CSV prints only counts
+func (report *Report) Print(w io.Writer, format string) error {
+	if output.IsStructured(format) {
+		printed := *report
+		printed.Mismatches = append([]string{}, report.Mismatches...)
+		printed.Notes = append([]string{}, report.Notes...)
+		return json.NewEncoder(w).Encode(printed)
+	}
+
+	if err := output.Print(w, format, report.Counts, countColumns); err != nil || format != output.Table {
+		return err
+	}
+	for _, section := range []struct {
+		title string
+		lines []string
+	}{{"Mismatches", report.Mismatches}, {"Notes", report.Notes}} {
+		if len(section.lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n", section.title)
+		for _, line := range section.lines {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+	if report.DryRun {
+		fmt.Fprintln(w, "\nDry run: nothing was written to the database.")
+	}
+	return nil
+}
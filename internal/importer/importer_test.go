@@ -0,0 +1,250 @@
+package importer
+
+import (
+	"GOHW-1/internal/db"
+	"GOHW-1/internal/model"
+	"GOHW-1/internal/storage"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sort"
+	"testing"
+	"time"
+)
+
+type memorySource struct {
+	available    []storage.OrderDTO
+	refunded     []storage.OrderDTO
+	pickUpPoints []model.PickUpPoint
+}
+
+func (s *memorySource) AvailableOrders() ([]storage.OrderDTO, error) { return s.available, nil }
+
+func (s *memorySource) RefundedOrders() ([]storage.OrderDTO, error) { return s.refunded, nil }
+
+func (s *memorySource) PickUpPointsRead() ([]model.PickUpPoint, error) { return s.pickUpPoints, nil }
+
+// memoryDatabase restores records when the transaction function fails, like a rollback
+type memoryDatabase struct {
+	orders       map[int]model.StoredOrder
+	pickUpPoints map[int64]model.PickUpPoint
+}
+
+func newMemoryDatabase() *memoryDatabase {
+	return &memoryDatabase{orders: map[int]model.StoredOrder{}, pickUpPoints: map[int64]model.PickUpPoint{}}
+}
+
+func (d *memoryDatabase) WithTx(ctx context.Context, fn func(ctx context.Context) error, _ ...db.TxOption) error {
+	orders := make(map[int]model.StoredOrder, len(d.orders))
+	for id, order := range d.orders {
+		orders[id] = order
+	}
+	pickUpPoints := make(map[int64]model.PickUpPoint, len(d.pickUpPoints))
+	for id, pickUpPoint := range d.pickUpPoints {
+		pickUpPoints[id] = pickUpPoint
+	}
+	if err := fn(ctx); err != nil {
+		d.orders, d.pickUpPoints = orders, pickUpPoints
+		return err
+	}
+	return nil
+}
+
+type memoryOrders struct{ *memoryDatabase }
+
+func (r memoryOrders) Upsert(_ context.Context, order model.StoredOrder) error {
+	r.orders[order.ID] = order
+	return nil
+}
+
+func (r memoryOrders) List(_ context.Context) ([]model.StoredOrder, error) {
+	orders := make([]model.StoredOrder, 0, len(r.orders))
+	for _, order := range r.orders {
+		orders = append(orders, order)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return orders, nil
+}
+
+type memoryPickUpPoints struct{ *memoryDatabase }
+
+func (r memoryPickUpPoints) Upsert(_ context.Context, pickUpPoint model.PickUpPoint) error {
+	r.pickUpPoints[pickUpPoint.ID] = pickUpPoint
+	return nil
+}
+
+func (r memoryPickUpPoints) List(_ context.Context) ([]model.PickUpPoint, error) {
+	pickUpPoints := make([]model.PickUpPoint, 0, len(r.pickUpPoints))
+	for _, pickUpPoint := range r.pickUpPoints {
+		pickUpPoints = append(pickUpPoints, pickUpPoint)
+	}
+	sort.Slice(pickUpPoints, func(i, j int) bool { return pickUpPoints[i].ID < pickUpPoints[j].ID })
+	return pickUpPoints, nil
+}
+
+func (r memoryPickUpPoints) SyncIDSequence(_ context.Context) error { return nil }
+
+func newTestImporter(source *memorySource, database *memoryDatabase) *Importer {
+	return New(source, database, memoryOrders{database}, memoryPickUpPoints{database})
+}
+
+func testSource() *memorySource {
+	expirationDate := time.Date(2024, 4, 10, 12, 0, 0, 0, time.UTC)
+	givenTime := time.Date(2024, 4, 2, 12, 0, 0, 0, time.UTC)
+	return &memorySource{
+		available: []storage.OrderDTO{
+			{ID: 1, ClientID: 10, ExpirationDate: expirationDate, Weight: 1, Price: 100, Packaging: "box"},
+			{ID: 2, ClientID: 10, ExpirationDate: expirationDate, Weight: 2, Price: 200, Packaging: "film",
+				PickUpPointID: 1, IsGiven: true, GivenTime: givenTime},
+		},
+		refunded: []storage.OrderDTO{
+			{ID: 3, ClientID: 20, ExpirationDate: expirationDate, Weight: 3, Price: 300, Packaging: "bag",
+				IsGiven: true, GivenTime: givenTime},
+		},
+		pickUpPoints: []model.PickUpPoint{
+			{ID: 1, Name: "Central", Address: "Main street, 1", Contact: "+7 999 000-00-01"},
+			{ID: 5, Name: "North", Address: "North street, 5", Contact: "+7 999 000-00-05"},
+		},
+	}
+}
+
+func TestImporter_Run(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	t.Run("smoke test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		database := newMemoryDatabase()
+		importer := newTestImporter(testSource(), database)
+
+		// act
+		report, err := importer.Run(ctx, false)
+
+		// assert
+		require.NoError(t, err)
+		assert.Empty(t, report.Mismatches)
+		assert.Empty(t, report.Notes)
+		assert.False(t, report.DryRun)
+		assert.Equal(t, []Count{
+			{Entity: "pick-up points", File: 2, Database: 2},
+			{Entity: "available orders", File: 1, Database: 1},
+			{Entity: "given orders", File: 1, Database: 1},
+			{Entity: "refunded orders", File: 1, Database: 1},
+		}, report.Counts)
+		assert.Equal(t, int64(5), database.pickUpPoints[5].ID)
+		assert.Equal(t, model.OrderGiven, database.orders[2].State)
+		require.NotNil(t, database.orders[2].GivenTime)
+		assert.Nil(t, database.orders[1].GivenTime)
+	})
+	t.Run("run again test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		database := newMemoryDatabase()
+		source := testSource()
+		importer := newTestImporter(source, database)
+		_, err := importer.Run(ctx, false)
+		require.NoError(t, err)
+		source.available[0].Price = 150
+
+		// act
+		report, err := importer.Run(ctx, false)
+
+		// assert
+		require.NoError(t, err)
+		assert.Empty(t, report.Mismatches)
+		assert.Len(t, database.orders, 3)
+		assert.Len(t, database.pickUpPoints, 2)
+		assert.Equal(t, 150.0, database.orders[1].Price)
+	})
+	t.Run("dry run test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		database := newMemoryDatabase()
+		importer := newTestImporter(testSource(), database)
+
+		// act
+		report, err := importer.Run(ctx, true)
+
+		// assert
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, Count{Entity: "pick-up points", File: 2, Database: 2}, report.Counts[0])
+		assert.Empty(t, database.orders)
+		assert.Empty(t, database.pickUpPoints)
+	})
+	t.Run("order in both files test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		database := newMemoryDatabase()
+		source := testSource()
+		refunded := source.available[0]
+		refunded.Price = 50
+		source.refunded = append(source.refunded, refunded)
+		importer := newTestImporter(source, database)
+
+		// act
+		report, err := importer.Run(ctx, false)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"order 1 is in both files, it is imported as available"}, report.Notes)
+		assert.Equal(t, model.OrderAvailable, database.orders[1].State)
+		assert.Equal(t, 100.0, database.orders[1].Price)
+	})
+	t.Run("records only in the database test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		database := newMemoryDatabase()
+		database.pickUpPoints[7] = model.PickUpPoint{ID: 7, Name: "West"}
+		database.orders[9] = model.StoredOrder{Order: model.Order{ID: 9}, State: model.OrderAvailable}
+		importer := newTestImporter(testSource(), database)
+
+		// act
+		report, err := importer.Run(ctx, false)
+
+		// assert
+		require.NoError(t, err)
+		assert.Empty(t, report.Mismatches)
+		assert.Equal(t, []string{"pick-up point 7 is only in the database", "order 9 is only in the database"}, report.Notes)
+		assert.Equal(t, Count{Entity: "available orders", File: 1, Database: 2}, report.Counts[1])
+	})
+}
+
+func TestSameOrder(t *testing.T) {
+	t.Parallel()
+	givenTime := time.Date(2024, 4, 2, 12, 0, 0, 123456789, time.UTC)
+	databaseGivenTime := givenTime.Truncate(time.Microsecond)
+	order := model.StoredOrder{Order: model.Order{ID: 1, ExpirationDate: givenTime}, State: model.OrderGiven, GivenTime: &givenTime}
+	tests := []struct {
+		name  string
+		other model.StoredOrder
+		want  bool
+	}{
+		{
+			name:  "database precision test",
+			other: model.StoredOrder{Order: model.Order{ID: 1, ExpirationDate: databaseGivenTime}, State: model.OrderGiven, GivenTime: &databaseGivenTime},
+			want:  true,
+		},
+		{
+			name:  "missing given time test",
+			other: model.StoredOrder{Order: model.Order{ID: 1, ExpirationDate: givenTime}, State: model.OrderGiven},
+			want:  false,
+		},
+		{
+			name:  "other state test",
+			other: model.StoredOrder{Order: model.Order{ID: 1, ExpirationDate: givenTime}, State: model.OrderRefunded, GivenTime: &givenTime},
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// act
+			same := sameOrder(order, tt.other)
+
+			// assert
+			assert.Equal(t, tt.want, same)
+		})
+	}
+}
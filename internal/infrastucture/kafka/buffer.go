@@ -0,0 +1,123 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const bufferFileExtension = ".event"
+
+var errBufferFull = errors.New("kafka buffer is full")
+
+// diskBuffer is a bounded FIFO queue that keeps every event in its own file named by a sequence number,
+// so events survive restarts and are replayed in the order they were buffered
+type diskBuffer struct {
+	dir       string
+	maxEvents int
+	head      uint64 // sequence number of the oldest event
+	tail      uint64 // sequence number of the next event
+	mutex     sync.Mutex
+}
+
+func openDiskBuffer(dir string, maxEvents int) (*diskBuffer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("cannot create kafka buffer directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read kafka buffer directory: %w", err)
+	}
+
+	buffer := &diskBuffer{dir: dir, maxEvents: maxEvents}
+	isFirst := true
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, bufferFileExtension) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, bufferFileExtension), 10, 64)
+		if err != nil {
+			continue
+		}
+		if isFirst || seq < buffer.head {
+			buffer.head = seq
+		}
+		if isFirst || seq >= buffer.tail {
+			buffer.tail = seq + 1
+		}
+		isFirst = false
+	}
+	return buffer, nil
+}
+
+// Len returns the number of buffered events
+func (b *diskBuffer) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return int(b.tail - b.head)
+}
+
+// Push appends an event to the end of the queue
+func (b *diskBuffer) Push(data []byte) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if int(b.tail-b.head) >= b.maxEvents {
+		return errBufferFull
+	}
+
+	tmpName := filepath.Join(b.dir, "tmp-"+strconv.FormatUint(b.tail, 10))
+	if err := os.WriteFile(tmpName, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, b.fileName(b.tail)); err != nil {
+		return err
+	}
+	b.tail++
+	return nil
+}
+
+// Peek returns the oldest event without removing it
+func (b *diskBuffer) Peek() ([]byte, bool, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for b.head < b.tail {
+		data, err := os.ReadFile(b.fileName(b.head))
+		if errors.Is(err, os.ErrNotExist) {
+			// The file was removed by hand, skip the gap
+			b.head++
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return data, true, nil
+	}
+	return nil, false, nil
+}
+
+// Pop removes the oldest event
+func (b *diskBuffer) Pop() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.head == b.tail {
+		return nil
+	}
+	if err := os.Remove(b.fileName(b.head)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	b.head++
+	return nil
+}
+
+func (b *diskBuffer) fileName(seq uint64) string {
+	return filepath.Join(b.dir, fmt.Sprintf("%020d%s", seq, bufferFileExtension))
+}
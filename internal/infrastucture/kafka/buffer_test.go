@@ -0,0 +1,73 @@
+package kafka
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDiskBuffer(t *testing.T) {
+	t.Parallel()
+	t.Run("smoke test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		buffer, err := openDiskBuffer(t.TempDir(), 10)
+		require.NoError(t, err)
+
+		// act
+		require.NoError(t, buffer.Push([]byte("first")))
+		require.NoError(t, buffer.Push([]byte("second")))
+		first, ok, err := buffer.Peek()
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.NoError(t, buffer.Pop())
+		second, _, _ := buffer.Peek()
+		require.NoError(t, buffer.Pop())
+		_, ok, err = buffer.Peek()
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, "first", string(first))
+		assert.Equal(t, "second", string(second))
+		assert.False(t, ok)
+		assert.Equal(t, 0, buffer.Len())
+	})
+	t.Run("full buffer test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		buffer, err := openDiskBuffer(t.TempDir(), 1)
+		require.NoError(t, err)
+		require.NoError(t, buffer.Push([]byte("first")))
+
+		// act
+		err = buffer.Push([]byte("second"))
+
+		// assert
+		assert.ErrorIs(t, err, errBufferFull)
+		assert.Equal(t, 1, buffer.Len())
+	})
+	t.Run("reopen keeps order test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		dir := t.TempDir()
+		buffer, err := openDiskBuffer(dir, 10)
+		require.NoError(t, err)
+		for _, event := range []string{"first", "second", "third"} {
+			require.NoError(t, buffer.Push([]byte(event)))
+		}
+		require.NoError(t, buffer.Pop())
+
+		// act
+		reopened, err := openDiskBuffer(dir, 10)
+		require.NoError(t, err)
+		data, ok, err := reopened.Peek()
+
+		// assert
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, "second", string(data))
+		assert.Equal(t, 2, reopened.Len())
+		require.NoError(t, reopened.Push([]byte("fourth")))
+		assert.Equal(t, 3, reopened.Len())
+	})
+}
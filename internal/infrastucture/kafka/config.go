@@ -0,0 +1,169 @@
+package kafka
+
+import (
+	"GOHW-1/internal/configuration"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/pkg/errors"
+	"github.com/xdg-go/scram"
+	"os"
+	"time"
+)
+
+// NewConfig builds sarama settings shared by the producer and the consumer group
+func NewConfig(kafkaConfig *configuration.KafkaConfig) (*sarama.Config, error) {
+	config := sarama.NewConfig()
+	config.ClientID = kafkaConfig.ClientID
+
+	config.Version = sarama.MaxVersion
+	if kafkaConfig.Version != "" {
+		version, err := sarama.ParseKafkaVersion(kafkaConfig.Version)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid kafka version")
+		}
+		config.Version = version
+	}
+
+	// Producer
+	switch kafkaConfig.Acks {
+	case "none":
+		config.Producer.RequiredAcks = sarama.NoResponse
+	case "leader":
+		config.Producer.RequiredAcks = sarama.WaitForLocal
+	default:
+		config.Producer.RequiredAcks = sarama.WaitForAll
+	}
+
+	switch kafkaConfig.Compression {
+	case "gzip":
+		config.Producer.Compression = sarama.CompressionGZIP
+	case "snappy":
+		config.Producer.Compression = sarama.CompressionSnappy
+	case "lz4":
+		config.Producer.Compression = sarama.CompressionLZ4
+	case "zstd":
+		config.Producer.Compression = sarama.CompressionZSTD
+	default:
+		config.Producer.Compression = sarama.CompressionNone
+	}
+
+	switch kafkaConfig.Partitioner {
+	case "hash":
+		config.Producer.Partitioner = sarama.NewHashPartitioner
+	case "random":
+		config.Producer.Partitioner = sarama.NewRandomPartitioner
+	default:
+		config.Producer.Partitioner = sarama.NewRoundRobinPartitioner
+	}
+
+	config.Producer.Flush.Messages = kafkaConfig.BatchSize
+	config.Producer.Flush.Bytes = kafkaConfig.BatchBytes
+	config.Producer.Flush.Frequency = kafkaConfig.FlushFrequency
+
+	config.Producer.Return.Successes = false
+	config.Producer.Return.Errors = true
+
+	// Consumer
+	config.Consumer.Offsets.Initial = sarama.OffsetNewest
+	if kafkaConfig.InitialOffset == "oldest" {
+		config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	}
+	config.Consumer.Group.ResetInvalidOffsets = true
+
+	config.Consumer.Group.Heartbeat.Interval = 3 * time.Second
+	config.Consumer.Group.Session.Timeout = time.Minute
+	config.Consumer.Group.Rebalance.Timeout = time.Minute
+
+	config.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.BalanceStrategyRoundRobin}
+
+	// Security
+	if kafkaConfig.SASL.Enabled {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.User = kafkaConfig.SASL.User
+		config.Net.SASL.Password = kafkaConfig.SASL.Password
+		config.Net.SASL.Handshake = true
+
+		switch kafkaConfig.SASL.Mechanism {
+		case "SCRAM-SHA-256":
+			config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+				return &scramClient{hashGenerator: scram.SHA256}
+			}
+		case "SCRAM-SHA-512":
+			config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+				return &scramClient{hashGenerator: scram.SHA512}
+			}
+		default:
+			config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		}
+	}
+
+	if kafkaConfig.TLS.Enabled {
+		tlsConfig, err := newTLSConfig(kafkaConfig.TLS)
+		if err != nil {
+			return nil, err
+		}
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid kafka configuration")
+	}
+	return config, nil
+}
+
+func newTLSConfig(tlsSettings configuration.KafkaTLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: tlsSettings.InsecureSkipVerify,
+	}
+
+	if tlsSettings.CAFile != "" {
+		caCert, err := os.ReadFile(tlsSettings.CAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot read kafka CA file")
+		}
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("kafka CA file %s contains no certificates", tlsSettings.CAFile)
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+
+	if tlsSettings.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(tlsSettings.CertFile, tlsSettings.KeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot load kafka client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// scramClient implements sarama.SCRAMClient
+type scramClient struct {
+	*scram.Client
+	*scram.ClientConversation
+	hashGenerator scram.HashGeneratorFcn
+}
+
+func (client *scramClient) Begin(userName, password, authzID string) error {
+	scramClient, err := client.hashGenerator.NewClient(userName, password, authzID)
+	if err != nil {
+		return err
+	}
+	client.Client = scramClient
+	client.ClientConversation = scramClient.NewConversation()
+	return nil
+}
+
+func (client *scramClient) Step(challenge string) (string, error) {
+	return client.ClientConversation.Step(challenge)
+}
+
+func (client *scramClient) Done() bool {
+	return client.ClientConversation.Done()
+}
@@ -1,26 +1,47 @@
 package kafka
 
 import (
+	"GOHW-1/internal/logger"
+	"GOHW-1/internal/metrics"
+	"GOHW-1/internal/tracing"
 	"encoding/json"
-	"fmt"
 	"github.com/IBM/sarama"
-	"log"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"sync/atomic"
 	"time"
 )
 
 type LoggingMessage struct {
-	Method string
-	URI    string
-	Time   time.Time
+	Method    string
+	URI       string
+	Route     string
+	Status    int
+	Duration  time.Duration
+	Time      time.Time
+	RequestID string `json:",omitempty"`
 }
 
+// consumerLagInterval is how often the lag of a claimed partition is updated while no messages arrive
+const consumerLagInterval = 10 * time.Second
+
+// MessageHandler is called for every claimed logging message
+type MessageHandler func(message LoggingMessage)
+
 type ConsumerGroup struct {
-	ready chan bool
+	ready   chan bool
+	handler MessageHandler
+	logger  *slog.Logger
+	member  atomic.Bool
 }
 
-func NewConsumerGroup() ConsumerGroup {
-	return ConsumerGroup{
-		ready: make(chan bool),
+func NewConsumerGroup(handler MessageHandler, logger *slog.Logger) *ConsumerGroup {
+	return &ConsumerGroup{
+		ready:   make(chan bool),
+		handler: handler,
+		logger:  logger,
 	}
 }
 
@@ -28,33 +49,104 @@ func (consumer *ConsumerGroup) Ready() <-chan bool {
 	return consumer.ready
 }
 
-// Setup starts a new session, before ConsumeClaim
+// Member reports whether the consumer has partitions of a running session, it is not during rebalances
+func (consumer *ConsumerGroup) Member() bool {
+	return consumer.member.Load()
+}
+
+// Started reports whether a session was set up, i.e. the consumer has joined its group at least once
+func (consumer *ConsumerGroup) Started() bool {
+	select {
+	case <-consumer.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// Setup starts a new session, before ConsumeClaim. It is called again after every rebalance
 func (consumer *ConsumerGroup) Setup(_ sarama.ConsumerGroupSession) error {
-	close(consumer.ready)
+	select {
+	case <-consumer.ready:
+	default:
+		close(consumer.ready)
+	}
+	consumer.member.Store(true)
 
 	return nil
 }
 
-// Cleanup finishes the session, after all ConsumeClaim finish
-func (consumer *ConsumerGroup) Cleanup(_ sarama.ConsumerGroupSession) error {
+// Cleanup finishes the session, after all ConsumeClaim finish. Lag of the claimed partitions is removed,
+// after a rebalance they may be read by another member
+func (consumer *ConsumerGroup) Cleanup(session sarama.ConsumerGroupSession) error {
+	consumer.member.Store(false)
+	for topic, partitions := range session.Claims() {
+		for _, partition := range partitions {
+			metrics.DeleteConsumerLag(topic, partition)
+		}
+	}
 	return nil
 }
 
-// ConsumeClaim reads while the session is running
+// ConsumeClaim reads while the session is running. The lag of the partition is updated after every message
+// and every consumerLagInterval, so that it grows while the consumer is stuck or paused
 func (consumer *ConsumerGroup) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	// next is the offset of the next message to read, negative until it is known
+	next := claim.InitialOffset()
+	setLag := func() {
+		if next >= 0 {
+			metrics.SetConsumerLag(claim.Topic(), claim.Partition(), claim.HighWaterMarkOffset()-next)
+		}
+	}
+	setLag()
+	ticker := time.NewTicker(consumerLagInterval)
+	defer ticker.Stop()
+
 	for {
 		select {
-		case message := <-claim.Messages():
+		case <-ticker.C:
+			setLag()
+		case message, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			ctx := ExtractTraceContext(session.Context(), message)
+			if requestID := header(message, logger.RequestIDHeader); requestID != "" {
+				ctx = logger.WithRequestID(ctx, requestID)
+			}
+			ctx, span := tracing.Tracer().Start(ctx, message.Topic+" process", trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(semconv.MessagingSystemKafka, semconv.MessagingOperationDeliver,
+					semconv.MessagingDestinationName(message.Topic), semconv.MessagingKafkaDestinationPartition(int(message.Partition)),
+					semconv.MessagingKafkaMessageOffset(int(message.Offset))))
 			lm := LoggingMessage{}
 			if err := json.Unmarshal(message.Value, &lm); err != nil {
-				fmt.Println("Consumer group error", err)
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				consumer.logger.ErrorContext(ctx, "cannot decode the logging message", "offset", message.Offset, "error", err)
+			} else {
+				if consumer.handler != nil {
+					consumer.handler(lm)
+				}
+				consumer.logger.DebugContext(ctx, "message claimed", "method", lm.Method, "uri", lm.URI,
+					"status", lm.Status, "duration", lm.Duration, "time", lm.Time)
 			}
-
-			log.Printf("Message claimed: \"Method: %s, URI: %s, Time: %s\"", lm.Method, lm.URI, lm.Time)
+			span.End()
 
 			session.MarkMessage(message, "")
+			next = message.Offset + 1
+			setLag()
 		case <-session.Context().Done():
 			return nil
 		}
 	}
 }
+
+// header returns the value of the message header with the key, empty if there is none
+func header(message *sarama.ConsumerMessage, key string) string {
+	for _, recordHeader := range message.Headers {
+		if recordHeader != nil && string(recordHeader.Key) == key {
+			return string(recordHeader.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,112 @@
+package kafka
+
+import (
+	"GOHW-1/internal/metrics"
+	"context"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeSession is a session of the claims that runs until its context is cancelled
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	ctx    context.Context
+	claims map[string][]int32
+}
+
+func (s fakeSession) Claims() map[string][]int32                  { return s.claims }
+func (s fakeSession) Context() context.Context                    { return s.ctx }
+func (s fakeSession) MarkMessage(*sarama.ConsumerMessage, string) {}
+
+// fakeClaim is a claim of partition 0 of the topic without messages
+type fakeClaim struct {
+	topic         string
+	initialOffset int64
+	highWaterMark int64
+}
+
+func (c fakeClaim) Topic() string                            { return c.topic }
+func (c fakeClaim) Partition() int32                         { return 0 }
+func (c fakeClaim) InitialOffset() int64                     { return c.initialOffset }
+func (c fakeClaim) HighWaterMarkOffset() int64               { return c.highWaterMark }
+func (c fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return nil }
+
+// consumerLag returns the lag of partition 0 of the topic and whether it is reported
+func consumerLag(t *testing.T, topic string) (float64, bool) {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "gohw_kafka_consumer_lag" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["topic"] == topic && labels["partition"] == strconv.Itoa(0) {
+				return metric.GetGauge().GetValue(), true
+			}
+		}
+	}
+	return 0, false
+}
+
+func TestConsumerGroup_Started(t *testing.T) {
+	t.Parallel()
+	t.Run("smoke test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		consumer := NewConsumerGroup(nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		session := fakeSession{ctx: context.Background()}
+		started := consumer.Started()
+
+		// act
+		setupErr := consumer.Setup(session)
+		cleanupErr := consumer.Cleanup(session)
+
+		// assert
+		assert.False(t, started)
+		assert.NoError(t, setupErr)
+		assert.NoError(t, cleanupErr)
+		assert.True(t, consumer.Started())
+		assert.False(t, consumer.Member())
+	})
+}
+
+func TestConsumerGroup_ConsumeClaim(t *testing.T) {
+	t.Parallel()
+	t.Run("lag without messages test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		const topic = "lag-without-messages"
+		consumer := NewConsumerGroup(nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		ctx, cancel := context.WithCancel(context.Background())
+		session := fakeSession{ctx: ctx, claims: map[string][]int32{topic: {0}}}
+		done := make(chan error)
+
+		// act
+		require.NoError(t, consumer.Setup(session))
+		go func() {
+			done <- consumer.ConsumeClaim(session, fakeClaim{topic: topic, initialOffset: 5, highWaterMark: 12})
+		}()
+
+		// assert
+		assert.Eventually(t, func() bool {
+			lag, ok := consumerLag(t, topic)
+			return ok && lag == 7
+		}, time.Second, 10*time.Millisecond)
+		cancel()
+		require.NoError(t, <-done)
+		require.NoError(t, consumer.Cleanup(session))
+		_, ok := consumerLag(t, topic)
+		assert.False(t, ok)
+	})
+}
@@ -1,60 +1,382 @@
 package kafka
 
 import (
-	"fmt"
+	"GOHW-1/internal/configuration"
+	"GOHW-1/internal/metrics"
+	"encoding/json"
 	"github.com/IBM/sarama"
 	"github.com/pkg/errors"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// inFlightDir is the directory of the disk buffer of events that failed after they were sent asynchronously
+const inFlightDir = "in_flight"
+
 type Producer struct {
 	brokers       []string
+	config        *sarama.Config
+	client        sarama.Client
 	asyncProducer sarama.AsyncProducer
+	syncProducer  sarama.SyncProducer
+	buffer        *diskBuffer
+	// inFlight keeps events that failed after they were sent asynchronously. They are older than events
+	// of buffer, which are buffered only while nothing is sent asynchronously, so they are replayed first
+	inFlight *diskBuffer
+	// failing is set when an event cannot be produced and cleared once one is produced or brokers answer again,
+	// the client does not notice that brokers went away on its own
+	failing       atomic.Bool
+	retryInterval time.Duration
+	logger        *slog.Logger
+	mutex         sync.RWMutex
+	done          chan struct{}
+	wg            sync.WaitGroup
 }
 
-func newAsyncProducer(brokers []string) (sarama.AsyncProducer, error) {
-	asyncProducerConfig := sarama.NewConfig()
+// bufferedMessage is the on-disk representation of a message that could not be delivered
+type bufferedMessage struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers []sarama.RecordHeader
+}
 
-	asyncProducerConfig.Producer.Partitioner = sarama.NewRoundRobinPartitioner
-	asyncProducerConfig.Producer.RequiredAcks = sarama.WaitForAll
+// NewProducer creates a kafka producer. When the disk buffer is configured, the producer is returned even if
+// brokers cannot be reached: events are buffered on disk and replayed in order once kafka is available.
+// Either way the producer reconnects and probes failing brokers every retry interval, if it is set
+func NewProducer(kafkaConfig *configuration.KafkaConfig, logger *slog.Logger) (*Producer, error) {
+	config, err := NewConfig(kafkaConfig)
+	if err != nil {
+		return nil, err
+	}
+	// Successes are needed by the sync producer that replays buffered events
+	config.Producer.Return.Successes = true
+
+	producer := &Producer{
+		brokers:       kafkaConfig.Brokers,
+		config:        config,
+		retryInterval: kafkaConfig.BufferRetryInterval,
+		logger:        logger,
+		done:          make(chan struct{}),
+	}
 
-	asyncProducerConfig.Producer.Return.Successes = false
-	asyncProducerConfig.Producer.Return.Errors = true
+	if kafkaConfig.BufferDir != "" {
+		producer.buffer, err = openDiskBuffer(kafkaConfig.BufferDir, kafkaConfig.BufferMaxEvents)
+		if err != nil {
+			return nil, err
+		}
+		producer.inFlight, err = openDiskBuffer(filepath.Join(kafkaConfig.BufferDir, inFlightDir), kafkaConfig.BufferMaxEvents)
+		if err != nil {
+			return nil, err
+		}
+		metrics.SetKafkaProducerBuffered(producer.Buffered())
+	}
+
+	if err := producer.connect(); err != nil {
+		if producer.buffer == nil {
+			return nil, errors.Wrap(err, "error with async kafka-producer")
+		}
+		logger.Warn("kafka is unavailable, events will be buffered", "dir", kafkaConfig.BufferDir, "error", err)
+	}
 
-	asyncProducer, err := sarama.NewAsyncProducer(brokers, asyncProducerConfig)
+	if producer.retryInterval > 0 {
+		producer.wg.Add(1)
+		go producer.replay()
+	}
+
+	return producer, nil
+}
+
+func (k *Producer) connect() error {
+	client, err := sarama.NewClient(k.brokers, k.config)
+	if err != nil {
+		return err
+	}
+	asyncProducer, err := sarama.NewAsyncProducerFromClient(client)
+	if err != nil {
+		client.Close()
+		return err
+	}
+	syncProducer, err := sarama.NewSyncProducerFromClient(client)
 	if err != nil {
-		return nil, errors.Wrap(err, "error with async kafka-producer")
+		asyncProducer.Close()
+		client.Close()
+		return err
 	}
 
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	select {
+	case <-k.done:
+		// Close has already been called while connecting
+		syncProducer.Close()
+		asyncProducer.Close()
+		client.Close()
+		return errors.New("kafka producer is closed")
+	default:
+	}
+
+	k.wg.Add(2)
 	go func() {
+		defer k.wg.Done()
+		for range asyncProducer.Successes() {
+			metrics.KafkaMessageProduced()
+			k.failing.Store(false)
+		}
+	}()
+	go func() {
+		defer k.wg.Done()
 		// Error и Retry топики можно использовать при получении ошибки
 		for e := range asyncProducer.Errors() {
-			fmt.Println(e.Error())
+			metrics.KafkaMessageFailed()
+			k.failing.Store(true)
+			k.logger.Error("cannot produce kafka event", "topic", e.Msg.Topic, "error", e.Err)
+			if err := k.push(k.inFlight, e.Msg); err != nil {
+				k.logger.Error("kafka event is lost", "error", err)
+			}
 		}
 	}()
 
-	return asyncProducer, nil
+	k.client = client
+	k.asyncProducer = asyncProducer
+	k.syncProducer = syncProducer
+	k.failing.Store(false)
+	return nil
+}
+
+// SendAsyncMessage sends the message or buffers it on disk while kafka is unavailable or older events
+// are still waiting to be replayed
+func (k *Producer) SendAsyncMessage(message *sarama.ProducerMessage) error {
+	k.mutex.RLock()
+	asyncProducer := k.asyncProducer
+	k.mutex.RUnlock()
+
+	if k.buffer == nil {
+		if asyncProducer == nil {
+			return errors.New("kafka producer is not connected")
+		}
+		asyncProducer.Input() <- message
+		return nil
+	}
+
+	if asyncProducer == nil || k.failing.Load() || k.Buffered() > 0 {
+		return k.spill(message)
+	}
+	select {
+	case asyncProducer.Input() <- message:
+		return nil
+	default:
+		// The input channel is full, which means brokers do not keep up
+		return k.spill(message)
+	}
+}
+
+// Connected reports whether the producer has a connection to the brokers and the last attempt to produce
+// an event or to reach the brokers did not fail
+func (k *Producer) Connected() bool {
+	return k.hasClient() && !k.failing.Load()
+}
+
+// hasClient reports whether the producer has an open client, which it keeps while brokers are failing
+func (k *Producer) hasClient() bool {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	return k.client != nil && !k.client.Closed()
+}
+
+// Buffered returns the number of events waiting in the disk buffer
+func (k *Producer) Buffered() int {
+	if k.buffer == nil {
+		return 0
+	}
+	return k.inFlight.Len() + k.buffer.Len()
+}
+
+// spill buffers the message after all buffered events
+func (k *Producer) spill(message *sarama.ProducerMessage) error {
+	return k.push(k.buffer, message)
 }
 
-func NewProducer(brokers []string) (*Producer, error) {
-	asyncProducer, err := newAsyncProducer(brokers)
+// push appends the message to the disk buffer
+func (k *Producer) push(buffer *diskBuffer, message *sarama.ProducerMessage) error {
+	if buffer == nil {
+		return errors.New("kafka buffer is disabled")
+	}
+
+	buffered := bufferedMessage{Topic: message.Topic, Headers: message.Headers}
+	var err error
+	if message.Key != nil {
+		if buffered.Key, err = message.Key.Encode(); err != nil {
+			return err
+		}
+	}
+	if message.Value != nil {
+		if buffered.Value, err = message.Value.Encode(); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.Marshal(buffered)
 	if err != nil {
-		return nil, errors.Wrap(err, "error with async kafka-producer")
+		return err
 	}
+	if err := buffer.Push(data); err != nil {
+		return err
+	}
+	metrics.SetKafkaProducerBuffered(k.Buffered())
+	return nil
+}
 
-	producer := &Producer{
-		brokers:       brokers,
-		asyncProducer: asyncProducer,
+// replay periodically reconnects to kafka, probes brokers while producing fails and sends buffered events in order
+func (k *Producer) replay() {
+	defer k.wg.Done()
+	ticker := time.NewTicker(k.retryInterval)
+	defer ticker.Stop()
+
+	lastReported := -1
+	for {
+		select {
+		case <-k.done:
+			return
+		case <-ticker.C:
+		}
+
+		if !k.hasClient() {
+			if err := k.connect(); err != nil {
+				continue
+			}
+			k.logger.Info("kafka is available again")
+		} else if k.failing.Load() && k.Buffered() == 0 {
+			// Buffered events probe brokers themselves, otherwise the producer would be failing until the next event
+			k.probe()
+		}
+
+		if k.buffer == nil {
+			continue
+		}
+
+		if err := k.flushBuffer(); err != nil {
+			k.logger.Warn("cannot replay buffered kafka events", "error", err)
+		}
+
+		if buffered := k.Buffered(); buffered != lastReported {
+			k.logger.Info("kafka buffer changed", "events", buffered)
+			lastReported = buffered
+		}
+	}
+}
+
+// probe clears failing once brokers answer a metadata request
+func (k *Producer) probe() {
+	k.mutex.RLock()
+	client := k.client
+	k.mutex.RUnlock()
+
+	if err := client.RefreshMetadata(); err != nil {
+		k.logger.Warn("kafka brokers are still failing", "error", err)
+		return
 	}
+	k.failing.Store(false)
+	k.logger.Info("kafka is available again")
+}
 
-	return producer, nil
+// flushBuffer sends events that failed in flight and then the other buffered events, each in order
+func (k *Producer) flushBuffer() error {
+	k.mutex.RLock()
+	syncProducer := k.syncProducer
+	k.mutex.RUnlock()
+
+	for _, buffer := range []*diskBuffer{k.inFlight, k.buffer} {
+		if err := k.flush(syncProducer, buffer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (k *Producer) flush(syncProducer sarama.SyncProducer, buffer *diskBuffer) error {
+	for {
+		select {
+		case <-k.done:
+			return nil
+		default:
+		}
+
+		data, ok, err := buffer.Peek()
+		if err != nil || !ok {
+			return err
+		}
+
+		var buffered bufferedMessage
+		if err := json.Unmarshal(data, &buffered); err != nil {
+			k.logger.Warn("dropping malformed buffered kafka event", "error", err)
+			if err := k.pop(buffer); err != nil {
+				return err
+			}
+			continue
+		}
+
+		message := &sarama.ProducerMessage{
+			Topic:     buffered.Topic,
+			Value:     sarama.ByteEncoder(buffered.Value),
+			Headers:   buffered.Headers,
+			Partition: -1,
+		}
+		if buffered.Key != nil {
+			message.Key = sarama.ByteEncoder(buffered.Key)
+		}
+		if _, _, err := syncProducer.SendMessage(message); err != nil {
+			metrics.KafkaMessageFailed()
+			k.failing.Store(true)
+			return err
+		}
+		metrics.KafkaMessageProduced()
+		k.failing.Store(false)
+		if err := k.pop(buffer); err != nil {
+			return err
+		}
+	}
 }
 
-func (k *Producer) SendAsyncMessage(message *sarama.ProducerMessage) {
-	k.asyncProducer.Input() <- message
+// pop removes the oldest event of the disk buffer once it is sent or dropped
+func (k *Producer) pop(buffer *diskBuffer) error {
+	if err := buffer.Pop(); err != nil {
+		return err
+	}
+	metrics.SetKafkaProducerBuffered(k.Buffered())
+	return nil
 }
 
+// Close stops replaying and waits until messages in flight are produced or kept in the disk buffer
+// for the next start. Closing the producer again does nothing
 func (k *Producer) Close() error {
-	if err := k.asyncProducer.Close(); err != nil {
+	k.mutex.Lock()
+	select {
+	case <-k.done:
+		k.mutex.Unlock()
+		return nil
+	default:
+		close(k.done)
+	}
+	if k.asyncProducer != nil {
+		// Failed messages come back from Errors, which is drained until the producer shuts down
+		k.asyncProducer.AsyncClose()
+	}
+	k.mutex.Unlock()
+	k.wg.Wait()
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	if k.client == nil {
+		return nil
+	}
+	if err := k.syncProducer.Close(); err != nil {
+		return errors.Wrap(err, "kafka.Connector.Close")
+	}
+	if err := k.client.Close(); err != nil {
 		return errors.Wrap(err, "kafka.Connector.Close")
 	}
 
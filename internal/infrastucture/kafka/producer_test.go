@@ -0,0 +1,143 @@
+package kafka
+
+import (
+	"GOHW-1/internal/configuration"
+	"encoding/json"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+const testTopic = "events"
+
+// mockBroker returns a broker leading the only partition of testTopic, produce requests fail with produceErr
+func mockBroker(t *testing.T, produceErr sarama.KError) *sarama.MockBroker {
+	t.Helper()
+	broker := sarama.NewMockBroker(t, 1)
+	t.Cleanup(broker.Close)
+	setProduceError(t, broker, produceErr)
+	return broker
+}
+
+func setProduceError(t *testing.T, broker *sarama.MockBroker, produceErr sarama.KError) {
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader(testTopic, 0, broker.BrokerID()),
+		"ProduceRequest": sarama.NewMockProduceResponse(t).SetError(testTopic, 0, produceErr),
+	})
+}
+
+// newTestProducer returns a producer to the broker, bufferDir is empty to disable the disk buffer
+func newTestProducer(t *testing.T, broker *sarama.MockBroker, retryInterval time.Duration, bufferDir string) *Producer {
+	t.Helper()
+	kafkaConfig := configuration.NewKafkaConfig()
+	kafkaConfig.Brokers = []string{broker.Addr()}
+	kafkaConfig.Version = "2.1.0"
+	kafkaConfig.BufferDir = bufferDir
+	kafkaConfig.BufferRetryInterval = retryInterval
+	producer, err := NewProducer(kafkaConfig, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	t.Cleanup(func() { producer.Close() })
+	return producer
+}
+
+func message() *sarama.ProducerMessage {
+	return &sarama.ProducerMessage{Topic: testTopic, Value: sarama.StringEncoder("event")}
+}
+
+// peekValue returns the value of the oldest event of the disk buffer
+func peekValue(t *testing.T, buffer *diskBuffer) string {
+	t.Helper()
+	data, ok, err := buffer.Peek()
+	require.NoError(t, err)
+	require.True(t, ok)
+	var buffered bufferedMessage
+	require.NoError(t, json.Unmarshal(data, &buffered))
+	return string(buffered.Value)
+}
+
+func TestProducer_Connected(t *testing.T) {
+	t.Parallel()
+	t.Run("smoke test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		producer := newTestProducer(t, mockBroker(t, sarama.ErrNoError), 0, "")
+
+		// act
+		err := producer.SendAsyncMessage(message())
+
+		// assert
+		require.NoError(t, err)
+		assert.True(t, producer.Connected())
+	})
+	t.Run("failed produce test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		broker := mockBroker(t, sarama.ErrMessageSizeTooLarge)
+		producer := newTestProducer(t, broker, 0, "")
+
+		// act
+		require.NoError(t, producer.SendAsyncMessage(message()))
+
+		// assert
+		assert.Eventually(t, func() bool { return !producer.Connected() }, 5*time.Second, 10*time.Millisecond)
+		setProduceError(t, broker, sarama.ErrNoError)
+		require.NoError(t, producer.SendAsyncMessage(message()))
+		assert.Eventually(t, producer.Connected, 5*time.Second, 10*time.Millisecond)
+	})
+	t.Run("probe test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		producer := newTestProducer(t, mockBroker(t, sarama.ErrNoError), 10*time.Millisecond, "")
+
+		// act
+		producer.failing.Store(true)
+
+		// assert
+		assert.Eventually(t, producer.Connected, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestProducer_Buffer(t *testing.T) {
+	t.Parallel()
+	t.Run("in flight order test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		broker := mockBroker(t, sarama.ErrMessageSizeTooLarge)
+		producer := newTestProducer(t, broker, 0, t.TempDir())
+		// sent like SendAsyncMessage does when nothing is buffered, but waiting for the producer to take it
+		producer.asyncProducer.Input() <- &sarama.ProducerMessage{Topic: testTopic, Value: sarama.StringEncoder("first")}
+		require.Eventually(t, func() bool { return producer.Buffered() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+		// act
+		require.NoError(t, producer.SendAsyncMessage(&sarama.ProducerMessage{Topic: testTopic, Value: sarama.StringEncoder("second")}))
+
+		// assert
+		assert.Equal(t, "first", peekValue(t, producer.inFlight))
+		assert.Equal(t, "second", peekValue(t, producer.buffer))
+		setProduceError(t, broker, sarama.ErrNoError)
+		require.NoError(t, producer.flushBuffer())
+		assert.Equal(t, 0, producer.Buffered())
+		assert.True(t, producer.Connected())
+	})
+	t.Run("close test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		producer := newTestProducer(t, mockBroker(t, sarama.ErrMessageSizeTooLarge), 0, t.TempDir())
+		producer.asyncProducer.Input() <- message()
+
+		// act
+		firstErr := producer.Close()
+		secondErr := producer.Close()
+
+		// assert
+		assert.NoError(t, firstErr)
+		assert.NoError(t, secondErr)
+		assert.Equal(t, 1, producer.inFlight.Len())
+	})
+}
@@ -0,0 +1,75 @@
+package kafka
+
+import (
+	"GOHW-1/internal/tracing"
+	"context"
+	"github.com/IBM/sarama"
+)
+
+// producerHeaders carries trace context in headers of a message being produced
+type producerHeaders struct {
+	headers *[]sarama.RecordHeader
+}
+
+func (carrier producerHeaders) Get(key string) string {
+	for _, header := range *carrier.headers {
+		if string(header.Key) == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (carrier producerHeaders) Set(key string, value string) {
+	for i, header := range *carrier.headers {
+		if string(header.Key) == key {
+			(*carrier.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*carrier.headers = append(*carrier.headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+}
+
+func (carrier producerHeaders) Keys() []string {
+	keys := make([]string, 0, len(*carrier.headers))
+	for _, header := range *carrier.headers {
+		keys = append(keys, string(header.Key))
+	}
+	return keys
+}
+
+// consumerHeaders carries trace context in headers of a consumed message
+type consumerHeaders []*sarama.RecordHeader
+
+func (carrier consumerHeaders) Get(key string) string {
+	for _, header := range carrier {
+		if header != nil && string(header.Key) == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+// Set is not used, consumed messages are read only
+func (carrier consumerHeaders) Set(string, string) {}
+
+func (carrier consumerHeaders) Keys() []string {
+	keys := make([]string, 0, len(carrier))
+	for _, header := range carrier {
+		if header != nil {
+			keys = append(keys, string(header.Key))
+		}
+	}
+	return keys
+}
+
+// InjectTraceContext adds W3C trace context of ctx to the headers
+func InjectTraceContext(ctx context.Context, headers []sarama.RecordHeader) []sarama.RecordHeader {
+	tracing.Propagator.Inject(ctx, producerHeaders{headers: &headers})
+	return headers
+}
+
+// ExtractTraceContext returns a copy of ctx with trace context of the consumed message
+func ExtractTraceContext(ctx context.Context, message *sarama.ConsumerMessage) context.Context {
+	return tracing.Propagator.Extract(ctx, consumerHeaders(message.Headers))
+}
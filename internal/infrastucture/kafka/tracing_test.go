@@ -0,0 +1,52 @@
+package kafka
+
+import (
+	"context"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+)
+
+func TestTraceContext(t *testing.T) {
+	t.Parallel()
+	t.Run("round trip test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+			SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+			TraceFlags: trace.FlagsSampled,
+		})
+		ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+		requestID := sarama.RecordHeader{Key: []byte("X-Request-ID"), Value: []byte("abc")}
+
+		// act
+		headers := InjectTraceContext(ctx, []sarama.RecordHeader{requestID})
+		consumed := &sarama.ConsumerMessage{}
+		for i := range headers {
+			consumed.Headers = append(consumed.Headers, &headers[i])
+		}
+		extracted := trace.SpanContextFromContext(ExtractTraceContext(context.Background(), consumed))
+
+		// assert
+		require.Len(t, headers, 2)
+		assert.Equal(t, requestID, headers[0])
+		assert.Equal(t, "traceparent", string(headers[1].Key))
+		assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", string(headers[1].Value))
+		assert.Equal(t, spanContext.TraceID(), extracted.TraceID())
+		assert.Equal(t, spanContext.SpanID(), extracted.SpanID())
+		assert.True(t, extracted.IsRemote())
+	})
+	t.Run("no trace test", func(t *testing.T) {
+		t.Parallel()
+		// act
+		headers := InjectTraceContext(context.Background(), nil)
+		extracted := trace.SpanContextFromContext(ExtractTraceContext(context.Background(), &sarama.ConsumerMessage{}))
+
+		// assert
+		assert.Empty(t, headers)
+		assert.False(t, extracted.IsValid())
+	})
+}
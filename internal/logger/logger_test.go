@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	t.Run("json request id test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var out bytes.Buffer
+		log := New(&out, FormatJSON, slog.LevelInfo).With("component", "test")
+		ctx := WithRequestID(context.Background(), "abc-123")
+
+		// act
+		log.InfoContext(ctx, "request", "status", 200)
+
+		// assert
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+		assert.Equal(t, "request", record["msg"])
+		assert.Equal(t, "test", record["component"])
+		assert.Equal(t, "abc-123", record["request_id"])
+		assert.Equal(t, float64(200), record["status"])
+	})
+	t.Run("text level test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var out bytes.Buffer
+		log := New(&out, FormatText, slog.LevelWarn)
+
+		// act
+		log.Info("skipped")
+		log.Warn("written")
+
+		// assert
+		assert.NotContains(t, out.String(), "skipped")
+		assert.Contains(t, out.String(), "msg=written")
+		assert.NotContains(t, out.String(), "request_id")
+	})
+}
+
+func TestValidRequestID(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{name: "generated test", id: NewRequestID(), want: true},
+		{name: "symbols test", id: "a-b_c.D9", want: true},
+		{name: "empty test", id: "", want: false},
+		{name: "space test", id: "a b", want: false},
+		{name: "too long test", id: strings.Repeat("a", 65), want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// act
+			got := ValidRequestID(tt.id)
+
+			// assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
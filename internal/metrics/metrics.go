@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const namespace = "gohw"
+
+// Operations with orders counted by OrderOperation
+const (
+	OrdersTaken    = "taken"
+	OrdersReturned = "returned"
+	OrdersGiven    = "given"
+	OrdersRefunded = "refunded"
+)
+
+// Registry holds all metrics of the process, Handler exposes it
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests to the API by method, route template and status",
+	}, []string{"method", "route", "status"})
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests to the API by method, route template and status",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+	kafkaProducerMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_producer_messages_total",
+		Help:      "Messages acknowledged by kafka (success) or failed to be produced (error)",
+	}, []string{"result"})
+	kafkaProducerBuffered = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "kafka_producer_buffered",
+		Help:      "Events waiting in the disk buffer to be produced to kafka",
+	})
+	kafkaConsumerLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "kafka_consumer_lag",
+		Help:      "Messages of the partition that the consumer group has not read yet",
+	}, []string{"topic", "partition"})
+	orderOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_total",
+		Help:      "Orders taken from couriers, returned to couriers, given to clients and refunded by clients",
+	}, []string{"operation"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpRequestDuration,
+		kafkaProducerMessages,
+		kafkaProducerBuffered,
+		kafkaConsumerLag,
+		orderOperations,
+	)
+	for _, result := range []string{"success", "error"} {
+		kafkaProducerMessages.WithLabelValues(result)
+	}
+	for _, operation := range []string{OrdersTaken, OrdersReturned, OrdersGiven, OrdersRefunded} {
+		orderOperations.WithLabelValues(operation)
+	}
+}
+
+// Handler serves the registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest counts a request to the API and its duration
+func ObserveRequest(method string, route string, status int, duration time.Duration) {
+	statusLabel := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, statusLabel).Inc()
+	httpRequestDuration.WithLabelValues(method, route, statusLabel).Observe(duration.Seconds())
+}
+
+// KafkaMessageProduced counts a message acknowledged by kafka
+func KafkaMessageProduced() {
+	kafkaProducerMessages.WithLabelValues("success").Inc()
+}
+
+// KafkaMessageFailed counts a message kafka failed to produce
+func KafkaMessageFailed() {
+	kafkaProducerMessages.WithLabelValues("error").Inc()
+}
+
+// SetKafkaProducerBuffered sets the number of events waiting in the disk buffer of the producer
+func SetKafkaProducerBuffered(events int) {
+	kafkaProducerBuffered.Set(float64(events))
+}
+
+// SetConsumerLag sets the number of messages of the partition after the last consumed one
+func SetConsumerLag(topic string, partition int32, lag int64) {
+	kafkaConsumerLag.WithLabelValues(topic, strconv.Itoa(int(partition))).Set(float64(max(lag, 0)))
+}
+
+// DeleteConsumerLag removes the lag of a partition that the consumer group member no longer reads
+func DeleteConsumerLag(topic string, partition int32) {
+	kafkaConsumerLag.DeleteLabelValues(topic, strconv.Itoa(int(partition)))
+}
+
+// OrderOperation counts orders of a successful operation, e.g. OrdersGiven
+func OrderOperation(operation string, orders int) {
+	orderOperations.WithLabelValues(operation).Add(float64(orders))
+}
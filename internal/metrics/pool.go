@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"errors"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector reads statistics of the connection pool on every scrape
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	constructingConns    *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "pgxpool", name), help, nil, nil)
+	}
+	return &poolCollector{
+		pool:                 pool,
+		acquiredConns:        desc("acquired_conns", "Connections currently in use"),
+		idleConns:            desc("idle_conns", "Idle connections in the pool"),
+		constructingConns:    desc("constructing_conns", "Connections being established"),
+		totalConns:           desc("total_conns", "All connections of the pool"),
+		maxConns:             desc("max_conns", "Maximum size of the pool"),
+		acquireCount:         desc("acquire_count_total", "Successful acquires of a connection"),
+		acquireDuration:      desc("acquire_duration_seconds_total", "Time spent acquiring connections"),
+		canceledAcquireCount: desc("canceled_acquire_count_total", "Acquires cancelled by the context"),
+		emptyAcquireCount:    desc("empty_acquire_count_total", "Acquires that waited for a connection because the pool was empty"),
+	}
+}
+
+func (collector *poolCollector) Describe(descs chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(collector, descs)
+}
+
+func (collector *poolCollector) Collect(metrics chan<- prometheus.Metric) {
+	stat := collector.pool.Stat()
+	gauge := func(desc *prometheus.Desc, value float64) {
+		metrics <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value)
+	}
+	counter := func(desc *prometheus.Desc, value float64) {
+		metrics <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value)
+	}
+	gauge(collector.acquiredConns, float64(stat.AcquiredConns()))
+	gauge(collector.idleConns, float64(stat.IdleConns()))
+	gauge(collector.constructingConns, float64(stat.ConstructingConns()))
+	gauge(collector.totalConns, float64(stat.TotalConns()))
+	gauge(collector.maxConns, float64(stat.MaxConns()))
+	counter(collector.acquireCount, float64(stat.AcquireCount()))
+	counter(collector.acquireDuration, stat.AcquireDuration().Seconds())
+	counter(collector.canceledAcquireCount, float64(stat.CanceledAcquireCount()))
+	counter(collector.emptyAcquireCount, float64(stat.EmptyAcquireCount()))
+}
+
+// RegisterPool exposes statistics of the PostgreSQL connection pool. The pool of the process is registered once
+func RegisterPool(pool *pgxpool.Pool) error {
+	err := Registry.Register(newPoolCollector(pool))
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if errors.As(err, &alreadyRegistered) {
+		return errors.New("a connection pool is already registered")
+	}
+	return err
+}
@@ -7,6 +7,9 @@ import (
 
 var ErrObjectNotFound = errors.New("not found")
 
+// ErrStorage wraps failures to read, decode or write stored objects, unlike operations rejected by business rules
+var ErrStorage = errors.New("storage failure")
+
 type Order struct {
 	ID             int
 	ClientID       int
@@ -14,6 +17,21 @@ type Order struct {
 	Weight         float64 // In kg
 	Price          float64
 	Packaging      string
+	PickUpPointID  int64 // 0 - the order is not bound to a pick-up point, default policy applies
+}
+
+// Order states in the database
+const (
+	OrderAvailable string = "available"
+	OrderGiven     string = "given"
+	OrderRefunded  string = "refunded"
+)
+
+// StoredOrder is an order with its state, GivenTime is nil until the order is given
+type StoredOrder struct {
+	Order
+	State     string
+	GivenTime *time.Time
 }
 
 type PickUpPoint struct {
@@ -41,3 +59,39 @@ func GetPackagingRules() map[string]PackagingRule {
 		Film:    {ExtraCost: 1},
 	}
 }
+
+// Policy holds business rules applied to orders
+type Policy struct {
+	RefundWindow time.Duration // How long after giving an order it can be refunded
+	PageSize     int           // Orders on a page of the refund list
+	MaxStorage   time.Duration // Longest time an order can be kept before its expiration date, 0 - no limit
+	PickUpGrace  time.Duration // How long after the expiration date the client can still receive the order
+	ReturnGrace  time.Duration // How long after the expiration date the order is kept before it can be returned to the courier
+}
+
+// Policies holds the default policy and policies of pick-up points that override it
+type Policies struct {
+	Default      Policy
+	PickUpPoints map[int64]Policy
+}
+
+// For returns the policy of the pick-up point
+func (policies Policies) For(pickUpPointID int64) Policy {
+	if policy, ok := policies.PickUpPoints[pickUpPointID]; ok {
+		return policy
+	}
+	return policies.Default
+}
+
+// RouteStats describes requests to one route over a time window, latencies are in milliseconds
+type RouteStats struct {
+	Route         string    `db:"route" json:"route"`
+	WindowSeconds int64     `db:"window_seconds" json:"window_seconds"`
+	Count         int64     `db:"count" json:"count"`
+	Errors        int64     `db:"errors" json:"errors"`
+	ErrorRate     float64   `db:"error_rate" json:"error_rate"`
+	P50           float64   `db:"p50_ms" json:"p50_ms"`
+	P95           float64   `db:"p95_ms" json:"p95_ms"`
+	P99           float64   `db:"p99_ms" json:"p99_ms"`
+	ComputedAt    time.Time `db:"computed_at" json:"computed_at"`
+}
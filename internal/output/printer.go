@@ -0,0 +1,174 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	Table     = "table"
+	JSON      = "json"
+	JSONLines = "jsonl"
+	CSV       = "csv"
+)
+
+// Formats lists supported output formats for flag descriptions
+var Formats = []string{Table, JSON, JSONLines, CSV}
+
+// Column describes one field of a listed item. Name is used as the table and CSV header and as the JSON key,
+// so field names are the same in every format
+type Column[T any] struct {
+	Name  string
+	Value func(item T) any
+}
+
+// ValidateFormat checks that the format is supported
+func ValidateFormat(format string) error {
+	for _, supported := range Formats {
+		if format == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format %q: expected one of %s", format, strings.Join(Formats, ", "))
+}
+
+// IsStructured reports whether the format is meant for scripts
+func IsStructured(format string) bool {
+	return format == JSON || format == JSONLines
+}
+
+// Print writes items in the given format
+func Print[T any](w io.Writer, format string, items []T, columns []Column[T]) error {
+	switch format {
+	case JSON:
+		return printJSON(w, items, columns)
+	case JSONLines:
+		return printJSONLines(w, items, columns)
+	case CSV:
+		return printCSV(w, items, columns)
+	case Table:
+		return printTable(w, items, columns)
+	default:
+		return ValidateFormat(format)
+	}
+}
+
+// PrintError writes an error in the given format: a JSON object for structured formats, plain text otherwise
+func PrintError(w io.Writer, format string, command string, err error) {
+	if !IsStructured(format) {
+		fmt.Fprintln(w, err)
+		return
+	}
+	errorJson, _ := json.Marshal(struct {
+		Command string `json:"command"`
+		Error   string `json:"error"`
+	}{command, err.Error()})
+	fmt.Fprintln(w, string(errorJson))
+}
+
+func printJSON[T any](w io.Writer, items []T, columns []Column[T]) error {
+	var buffer bytes.Buffer
+	buffer.WriteByte('[')
+	for i, item := range items {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		if err := writeObject(&buffer, item, columns); err != nil {
+			return err
+		}
+	}
+	buffer.WriteString("]\n")
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
+
+func printJSONLines[T any](w io.Writer, items []T, columns []Column[T]) error {
+	for _, item := range items {
+		var buffer bytes.Buffer
+		if err := writeObject(&buffer, item, columns); err != nil {
+			return err
+		}
+		buffer.WriteByte('\n')
+		if _, err := w.Write(buffer.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeObject writes an item as a JSON object with keys in column order
+func writeObject[T any](buffer *bytes.Buffer, item T, columns []Column[T]) error {
+	buffer.WriteByte('{')
+	for i, column := range columns {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		key, _ := json.Marshal(column.Name)
+		value, err := json.Marshal(column.Value(item))
+		if err != nil {
+			return err
+		}
+		buffer.Write(key)
+		buffer.WriteByte(':')
+		buffer.Write(value)
+	}
+	buffer.WriteByte('}')
+	return nil
+}
+
+func printCSV[T any](w io.Writer, items []T, columns []Column[T]) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header(columns)); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := writer.Write(row(item, columns)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func printTable[T any](w io.Writer, items []T, columns []Column[T]) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.ToUpper(strings.Join(header(columns), "\t")))
+	for _, item := range items {
+		fmt.Fprintln(writer, strings.Join(row(item, columns), "\t"))
+	}
+	return writer.Flush()
+}
+
+func header[T any](columns []Column[T]) []string {
+	names := make([]string, 0, len(columns))
+	for _, column := range columns {
+		names = append(names, column.Name)
+	}
+	return names
+}
+
+func row[T any](item T, columns []Column[T]) []string {
+	cells := make([]string, 0, len(columns))
+	for _, column := range columns {
+		cells = append(cells, formatCell(column.Value(item)))
+	}
+	return cells
+}
+
+func formatCell(value any) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,134 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type item struct {
+	id    int
+	name  string
+	price float64
+	date  time.Time
+}
+
+var itemColumns = []Column[item]{
+	{Name: "id", Value: func(i item) any { return i.id }},
+	{Name: "name", Value: func(i item) any { return i.name }},
+	{Name: "price", Value: func(i item) any { return i.price }},
+	{Name: "date", Value: func(i item) any { return i.date }},
+}
+
+var items = []item{
+	{id: 1, name: "box", price: 10.5, date: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)},
+	{id: 2, name: "bag, small", price: 3, date: time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC)},
+}
+
+func TestPrint(t *testing.T) {
+	t.Parallel()
+	t.Run("json test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var buffer bytes.Buffer
+
+		// act
+		err := Print(&buffer, JSON, items, itemColumns)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, `[{"id":1,"name":"box","price":10.5,"date":"2026-10-19T12:00:00Z"},`+
+			`{"id":2,"name":"bag, small","price":3,"date":"2026-10-20T12:00:00Z"}]`+"\n", buffer.String())
+	})
+	t.Run("empty json test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var buffer bytes.Buffer
+
+		// act
+		err := Print(&buffer, JSON, []item{}, itemColumns)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, "[]\n", buffer.String())
+	})
+	t.Run("jsonl test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var buffer bytes.Buffer
+
+		// act
+		err := Print(&buffer, JSONLines, items, itemColumns)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, `{"id":1,"name":"box","price":10.5,"date":"2026-10-19T12:00:00Z"}`+"\n"+
+			`{"id":2,"name":"bag, small","price":3,"date":"2026-10-20T12:00:00Z"}`+"\n", buffer.String())
+	})
+	t.Run("csv test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var buffer bytes.Buffer
+
+		// act
+		err := Print(&buffer, CSV, items, itemColumns)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, "id,name,price,date\n1,box,10.5,2026-10-19T12:00:00Z\n"+
+			"2,\"bag, small\",3,2026-10-20T12:00:00Z\n", buffer.String())
+	})
+	t.Run("table test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var buffer bytes.Buffer
+
+		// act
+		err := Print(&buffer, Table, items[:1], itemColumns)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, "ID  NAME  PRICE  DATE\n1   box   10.5   2026-10-19T12:00:00Z\n", buffer.String())
+	})
+	t.Run("unknown format test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var buffer bytes.Buffer
+
+		// act
+		err := Print(&buffer, "xml", items, itemColumns)
+
+		// assert
+		require.Error(t, err)
+		assert.Empty(t, buffer.String())
+	})
+}
+
+func TestPrintError(t *testing.T) {
+	t.Parallel()
+	t.Run("json test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var buffer bytes.Buffer
+
+		// act
+		PrintError(&buffer, JSON, "cl-orders", errors.New("client ID is not given or incorrect"))
+
+		// assert
+		assert.Equal(t, `{"command":"cl-orders","error":"client ID is not given or incorrect"}`+"\n", buffer.String())
+	})
+	t.Run("table test", func(t *testing.T) {
+		t.Parallel()
+		// arrange
+		var buffer bytes.Buffer
+
+		// act
+		PrintError(&buffer, Table, "cl-orders", errors.New("n value is incorrect"))
+
+		// assert
+		assert.Equal(t, "n value is incorrect\n", buffer.String())
+	})
+}
@@ -0,0 +1,550 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: gohw.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	PickUpPoints_CreatePickUpPoint_FullMethodName = "/gohw.v1.PickUpPoints/CreatePickUpPoint"
+	PickUpPoints_GetPickUpPoint_FullMethodName    = "/gohw.v1.PickUpPoints/GetPickUpPoint"
+	PickUpPoints_ListPickUpPoints_FullMethodName  = "/gohw.v1.PickUpPoints/ListPickUpPoints"
+	PickUpPoints_UpdatePickUpPoint_FullMethodName = "/gohw.v1.PickUpPoints/UpdatePickUpPoint"
+	PickUpPoints_DeletePickUpPoint_FullMethodName = "/gohw.v1.PickUpPoints/DeletePickUpPoint"
+)
+
+// PickUpPointsClient is the client API for PickUpPoints service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PickUpPointsClient interface {
+	CreatePickUpPoint(ctx context.Context, in *CreatePickUpPointRequest, opts ...grpc.CallOption) (*PickUpPoint, error)
+	// GetPickUpPoint fails with NOT_FOUND when there is no pick-up point with the ID
+	GetPickUpPoint(ctx context.Context, in *GetPickUpPointRequest, opts ...grpc.CallOption) (*PickUpPoint, error)
+	ListPickUpPoints(ctx context.Context, in *ListPickUpPointsRequest, opts ...grpc.CallOption) (*ListPickUpPointsResponse, error)
+	// UpdatePickUpPoint fails with NOT_FOUND when there is no pick-up point with the ID
+	UpdatePickUpPoint(ctx context.Context, in *UpdatePickUpPointRequest, opts ...grpc.CallOption) (*PickUpPoint, error)
+	// DeletePickUpPoint fails with NOT_FOUND when there is no pick-up point with the ID
+	DeletePickUpPoint(ctx context.Context, in *DeletePickUpPointRequest, opts ...grpc.CallOption) (*DeletePickUpPointResponse, error)
+}
+
+type pickUpPointsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPickUpPointsClient(cc grpc.ClientConnInterface) PickUpPointsClient {
+	return &pickUpPointsClient{cc}
+}
+
+func (c *pickUpPointsClient) CreatePickUpPoint(ctx context.Context, in *CreatePickUpPointRequest, opts ...grpc.CallOption) (*PickUpPoint, error) {
+	out := new(PickUpPoint)
+	err := c.cc.Invoke(ctx, PickUpPoints_CreatePickUpPoint_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pickUpPointsClient) GetPickUpPoint(ctx context.Context, in *GetPickUpPointRequest, opts ...grpc.CallOption) (*PickUpPoint, error) {
+	out := new(PickUpPoint)
+	err := c.cc.Invoke(ctx, PickUpPoints_GetPickUpPoint_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pickUpPointsClient) ListPickUpPoints(ctx context.Context, in *ListPickUpPointsRequest, opts ...grpc.CallOption) (*ListPickUpPointsResponse, error) {
+	out := new(ListPickUpPointsResponse)
+	err := c.cc.Invoke(ctx, PickUpPoints_ListPickUpPoints_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pickUpPointsClient) UpdatePickUpPoint(ctx context.Context, in *UpdatePickUpPointRequest, opts ...grpc.CallOption) (*PickUpPoint, error) {
+	out := new(PickUpPoint)
+	err := c.cc.Invoke(ctx, PickUpPoints_UpdatePickUpPoint_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pickUpPointsClient) DeletePickUpPoint(ctx context.Context, in *DeletePickUpPointRequest, opts ...grpc.CallOption) (*DeletePickUpPointResponse, error) {
+	out := new(DeletePickUpPointResponse)
+	err := c.cc.Invoke(ctx, PickUpPoints_DeletePickUpPoint_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PickUpPointsServer is the server API for PickUpPoints service.
+// All implementations must embed UnimplementedPickUpPointsServer
+// for forward compatibility
+type PickUpPointsServer interface {
+	CreatePickUpPoint(context.Context, *CreatePickUpPointRequest) (*PickUpPoint, error)
+	// GetPickUpPoint fails with NOT_FOUND when there is no pick-up point with the ID
+	GetPickUpPoint(context.Context, *GetPickUpPointRequest) (*PickUpPoint, error)
+	ListPickUpPoints(context.Context, *ListPickUpPointsRequest) (*ListPickUpPointsResponse, error)
+	// UpdatePickUpPoint fails with NOT_FOUND when there is no pick-up point with the ID
+	UpdatePickUpPoint(context.Context, *UpdatePickUpPointRequest) (*PickUpPoint, error)
+	// DeletePickUpPoint fails with NOT_FOUND when there is no pick-up point with the ID
+	DeletePickUpPoint(context.Context, *DeletePickUpPointRequest) (*DeletePickUpPointResponse, error)
+	mustEmbedUnimplementedPickUpPointsServer()
+}
+
+// UnimplementedPickUpPointsServer must be embedded to have forward compatible implementations.
+type UnimplementedPickUpPointsServer struct {
+}
+
+func (UnimplementedPickUpPointsServer) CreatePickUpPoint(context.Context, *CreatePickUpPointRequest) (*PickUpPoint, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePickUpPoint not implemented")
+}
+func (UnimplementedPickUpPointsServer) GetPickUpPoint(context.Context, *GetPickUpPointRequest) (*PickUpPoint, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPickUpPoint not implemented")
+}
+func (UnimplementedPickUpPointsServer) ListPickUpPoints(context.Context, *ListPickUpPointsRequest) (*ListPickUpPointsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPickUpPoints not implemented")
+}
+func (UnimplementedPickUpPointsServer) UpdatePickUpPoint(context.Context, *UpdatePickUpPointRequest) (*PickUpPoint, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePickUpPoint not implemented")
+}
+func (UnimplementedPickUpPointsServer) DeletePickUpPoint(context.Context, *DeletePickUpPointRequest) (*DeletePickUpPointResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePickUpPoint not implemented")
+}
+func (UnimplementedPickUpPointsServer) mustEmbedUnimplementedPickUpPointsServer() {}
+
+// UnsafePickUpPointsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PickUpPointsServer will
+// result in compilation errors.
+type UnsafePickUpPointsServer interface {
+	mustEmbedUnimplementedPickUpPointsServer()
+}
+
+func RegisterPickUpPointsServer(s grpc.ServiceRegistrar, srv PickUpPointsServer) {
+	s.RegisterService(&PickUpPoints_ServiceDesc, srv)
+}
+
+func _PickUpPoints_CreatePickUpPoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePickUpPointRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PickUpPointsServer).CreatePickUpPoint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PickUpPoints_CreatePickUpPoint_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PickUpPointsServer).CreatePickUpPoint(ctx, req.(*CreatePickUpPointRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PickUpPoints_GetPickUpPoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPickUpPointRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PickUpPointsServer).GetPickUpPoint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PickUpPoints_GetPickUpPoint_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PickUpPointsServer).GetPickUpPoint(ctx, req.(*GetPickUpPointRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PickUpPoints_ListPickUpPoints_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPickUpPointsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PickUpPointsServer).ListPickUpPoints(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PickUpPoints_ListPickUpPoints_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PickUpPointsServer).ListPickUpPoints(ctx, req.(*ListPickUpPointsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PickUpPoints_UpdatePickUpPoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePickUpPointRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PickUpPointsServer).UpdatePickUpPoint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PickUpPoints_UpdatePickUpPoint_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PickUpPointsServer).UpdatePickUpPoint(ctx, req.(*UpdatePickUpPointRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PickUpPoints_DeletePickUpPoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePickUpPointRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PickUpPointsServer).DeletePickUpPoint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PickUpPoints_DeletePickUpPoint_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PickUpPointsServer).DeletePickUpPoint(ctx, req.(*DeletePickUpPointRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PickUpPoints_ServiceDesc is the grpc.ServiceDesc for PickUpPoints service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PickUpPoints_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gohw.v1.PickUpPoints",
+	HandlerType: (*PickUpPointsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreatePickUpPoint",
+			Handler:    _PickUpPoints_CreatePickUpPoint_Handler,
+		},
+		{
+			MethodName: "GetPickUpPoint",
+			Handler:    _PickUpPoints_GetPickUpPoint_Handler,
+		},
+		{
+			MethodName: "ListPickUpPoints",
+			Handler:    _PickUpPoints_ListPickUpPoints_Handler,
+		},
+		{
+			MethodName: "UpdatePickUpPoint",
+			Handler:    _PickUpPoints_UpdatePickUpPoint_Handler,
+		},
+		{
+			MethodName: "DeletePickUpPoint",
+			Handler:    _PickUpPoints_DeletePickUpPoint_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "gohw.proto",
+}
+
+const (
+	Orders_TakeOrder_FullMethodName        = "/gohw.v1.Orders/TakeOrder"
+	Orders_ReturnOrder_FullMethodName      = "/gohw.v1.Orders/ReturnOrder"
+	Orders_GiveOrders_FullMethodName       = "/gohw.v1.Orders/GiveOrders"
+	Orders_ListClientOrders_FullMethodName = "/gohw.v1.Orders/ListClientOrders"
+	Orders_RefundOrder_FullMethodName      = "/gohw.v1.Orders/RefundOrder"
+	Orders_ListRefunds_FullMethodName      = "/gohw.v1.Orders/ListRefunds"
+)
+
+// OrdersClient is the client API for Orders service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type OrdersClient interface {
+	// TakeOrder accepts an order from the courier, like cr-take
+	TakeOrder(ctx context.Context, in *TakeOrderRequest, opts ...grpc.CallOption) (*TakeOrderResponse, error)
+	// ReturnOrder returns an order to the courier, like cr-return
+	ReturnOrder(ctx context.Context, in *ReturnOrderRequest, opts ...grpc.CallOption) (*ReturnOrderResponse, error)
+	// GiveOrders gives orders to the client, like cl-give
+	GiveOrders(ctx context.Context, in *GiveOrdersRequest, opts ...grpc.CallOption) (*GiveOrdersResponse, error)
+	// ListClientOrders returns orders of the client, newest first, like cl-orders
+	ListClientOrders(ctx context.Context, in *ListClientOrdersRequest, opts ...grpc.CallOption) (*ListClientOrdersResponse, error)
+	// RefundOrder accepts a return from the client, like cl-refund
+	RefundOrder(ctx context.Context, in *RefundOrderRequest, opts ...grpc.CallOption) (*RefundOrderResponse, error)
+	// ListRefunds returns a page of refunded orders, like refund-list
+	ListRefunds(ctx context.Context, in *ListRefundsRequest, opts ...grpc.CallOption) (*ListRefundsResponse, error)
+}
+
+type ordersClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOrdersClient(cc grpc.ClientConnInterface) OrdersClient {
+	return &ordersClient{cc}
+}
+
+func (c *ordersClient) TakeOrder(ctx context.Context, in *TakeOrderRequest, opts ...grpc.CallOption) (*TakeOrderResponse, error) {
+	out := new(TakeOrderResponse)
+	err := c.cc.Invoke(ctx, Orders_TakeOrder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ordersClient) ReturnOrder(ctx context.Context, in *ReturnOrderRequest, opts ...grpc.CallOption) (*ReturnOrderResponse, error) {
+	out := new(ReturnOrderResponse)
+	err := c.cc.Invoke(ctx, Orders_ReturnOrder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ordersClient) GiveOrders(ctx context.Context, in *GiveOrdersRequest, opts ...grpc.CallOption) (*GiveOrdersResponse, error) {
+	out := new(GiveOrdersResponse)
+	err := c.cc.Invoke(ctx, Orders_GiveOrders_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ordersClient) ListClientOrders(ctx context.Context, in *ListClientOrdersRequest, opts ...grpc.CallOption) (*ListClientOrdersResponse, error) {
+	out := new(ListClientOrdersResponse)
+	err := c.cc.Invoke(ctx, Orders_ListClientOrders_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ordersClient) RefundOrder(ctx context.Context, in *RefundOrderRequest, opts ...grpc.CallOption) (*RefundOrderResponse, error) {
+	out := new(RefundOrderResponse)
+	err := c.cc.Invoke(ctx, Orders_RefundOrder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ordersClient) ListRefunds(ctx context.Context, in *ListRefundsRequest, opts ...grpc.CallOption) (*ListRefundsResponse, error) {
+	out := new(ListRefundsResponse)
+	err := c.cc.Invoke(ctx, Orders_ListRefunds_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OrdersServer is the server API for Orders service.
+// All implementations must embed UnimplementedOrdersServer
+// for forward compatibility
+type OrdersServer interface {
+	// TakeOrder accepts an order from the courier, like cr-take
+	TakeOrder(context.Context, *TakeOrderRequest) (*TakeOrderResponse, error)
+	// ReturnOrder returns an order to the courier, like cr-return
+	ReturnOrder(context.Context, *ReturnOrderRequest) (*ReturnOrderResponse, error)
+	// GiveOrders gives orders to the client, like cl-give
+	GiveOrders(context.Context, *GiveOrdersRequest) (*GiveOrdersResponse, error)
+	// ListClientOrders returns orders of the client, newest first, like cl-orders
+	ListClientOrders(context.Context, *ListClientOrdersRequest) (*ListClientOrdersResponse, error)
+	// RefundOrder accepts a return from the client, like cl-refund
+	RefundOrder(context.Context, *RefundOrderRequest) (*RefundOrderResponse, error)
+	// ListRefunds returns a page of refunded orders, like refund-list
+	ListRefunds(context.Context, *ListRefundsRequest) (*ListRefundsResponse, error)
+	mustEmbedUnimplementedOrdersServer()
+}
+
+// UnimplementedOrdersServer must be embedded to have forward compatible implementations.
+type UnimplementedOrdersServer struct {
+}
+
+func (UnimplementedOrdersServer) TakeOrder(context.Context, *TakeOrderRequest) (*TakeOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TakeOrder not implemented")
+}
+func (UnimplementedOrdersServer) ReturnOrder(context.Context, *ReturnOrderRequest) (*ReturnOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReturnOrder not implemented")
+}
+func (UnimplementedOrdersServer) GiveOrders(context.Context, *GiveOrdersRequest) (*GiveOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GiveOrders not implemented")
+}
+func (UnimplementedOrdersServer) ListClientOrders(context.Context, *ListClientOrdersRequest) (*ListClientOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListClientOrders not implemented")
+}
+func (UnimplementedOrdersServer) RefundOrder(context.Context, *RefundOrderRequest) (*RefundOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefundOrder not implemented")
+}
+func (UnimplementedOrdersServer) ListRefunds(context.Context, *ListRefundsRequest) (*ListRefundsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRefunds not implemented")
+}
+func (UnimplementedOrdersServer) mustEmbedUnimplementedOrdersServer() {}
+
+// UnsafeOrdersServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OrdersServer will
+// result in compilation errors.
+type UnsafeOrdersServer interface {
+	mustEmbedUnimplementedOrdersServer()
+}
+
+func RegisterOrdersServer(s grpc.ServiceRegistrar, srv OrdersServer) {
+	s.RegisterService(&Orders_ServiceDesc, srv)
+}
+
+func _Orders_TakeOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TakeOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrdersServer).TakeOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Orders_TakeOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrdersServer).TakeOrder(ctx, req.(*TakeOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Orders_ReturnOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReturnOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrdersServer).ReturnOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Orders_ReturnOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrdersServer).ReturnOrder(ctx, req.(*ReturnOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Orders_GiveOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GiveOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrdersServer).GiveOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Orders_GiveOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrdersServer).GiveOrders(ctx, req.(*GiveOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Orders_ListClientOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListClientOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrdersServer).ListClientOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Orders_ListClientOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrdersServer).ListClientOrders(ctx, req.(*ListClientOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Orders_RefundOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefundOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrdersServer).RefundOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Orders_RefundOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrdersServer).RefundOrder(ctx, req.(*RefundOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Orders_ListRefunds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRefundsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrdersServer).ListRefunds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Orders_ListRefunds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrdersServer).ListRefunds(ctx, req.(*ListRefundsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Orders_ServiceDesc is the grpc.ServiceDesc for Orders service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Orders_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gohw.v1.Orders",
+	HandlerType: (*OrdersServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "TakeOrder",
+			Handler:    _Orders_TakeOrder_Handler,
+		},
+		{
+			MethodName: "ReturnOrder",
+			Handler:    _Orders_ReturnOrder_Handler,
+		},
+		{
+			MethodName: "GiveOrders",
+			Handler:    _Orders_GiveOrders_Handler,
+		},
+		{
+			MethodName: "ListClientOrders",
+			Handler:    _Orders_ListClientOrders_Handler,
+		},
+		{
+			MethodName: "RefundOrder",
+			Handler:    _Orders_RefundOrder_Handler,
+		},
+		{
+			MethodName: "ListRefunds",
+			Handler:    _Orders_ListRefunds_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "gohw.proto",
+}
@@ -0,0 +1,42 @@
+package postgresql
+
+import (
+	"GOHW-1/internal/db"
+	"GOHW-1/internal/model"
+	"context"
+)
+
+type OrderRepo struct {
+	db db.Database
+}
+
+func NewOrders(database db.Database) *OrderRepo {
+	return &OrderRepo{db: database}
+}
+
+// Upsert creates the order or replaces the order with the same ID
+func (r *OrderRepo) Upsert(ctx context.Context, order model.StoredOrder) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO orders(id, client_id, expiration_date, weight, price, packaging, pick_up_point_id, state, given_time)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id) DO UPDATE SET client_id = $2, expiration_date = $3, weight = $4, price = $5, packaging = $6,
+			pick_up_point_id = $7, state = $8, given_time = $9`,
+		order.ID, order.ClientID, order.ExpirationDate, order.Weight, order.Price, order.Packaging, order.PickUpPointID,
+		order.State, order.GivenTime)
+	return err
+}
+
+// List retrieves all orders ordered by ID
+func (r *OrderRepo) List(ctx context.Context) ([]model.StoredOrder, error) {
+	var orders []model.StoredOrder
+	if err := r.db.Select(ctx, &orders, `SELECT id, client_id, expiration_date, weight, price, packaging, pick_up_point_id, state, given_time
+		FROM orders ORDER BY id`); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// DeleteAll removes all orders
+func (r *OrderRepo) DeleteAll(ctx context.Context) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM orders")
+	return err
+}
@@ -24,6 +24,21 @@ func (r *PickUpPointRepo) Create(ctx context.Context, pickUpPoint *model.PickUpP
 	return id, err
 }
 
+// Upsert creates the pick-up point with its ID or replaces the one with the same ID
+func (r *PickUpPointRepo) Upsert(ctx context.Context, pickUpPoint model.PickUpPoint) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO pick_up_points(id, name, address, contact) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET name = $2, address = $3, contact = $4`,
+		pickUpPoint.ID, pickUpPoint.Name, pickUpPoint.Address, pickUpPoint.Contact)
+	return err
+}
+
+// SyncIDSequence moves the ID sequence past the largest ID, so that Create does not reuse IDs given to Upsert
+func (r *PickUpPointRepo) SyncIDSequence(ctx context.Context) error {
+	_, err := r.db.Exec(ctx, `SELECT setval(pg_get_serial_sequence('pick_up_points', 'id'), COALESCE(MAX(id), 0) + 1, false)
+		FROM pick_up_points`)
+	return err
+}
+
 // GetByID return model.PickUpPoint by given ID
 func (r *PickUpPointRepo) GetByID(ctx context.Context, id int64) (*model.PickUpPoint, error) {
 	var pickUpPoint model.PickUpPoint
@@ -71,3 +86,9 @@ func (r *PickUpPointRepo) Delete(ctx context.Context, id int64) error {
 	}
 	return nil
 }
+
+// DeleteAll removes all pick-up points
+func (r *PickUpPointRepo) DeleteAll(ctx context.Context) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM pick_up_points")
+	return err
+}
@@ -0,0 +1,57 @@
+package postgresql
+
+import (
+	"GOHW-1/internal/db"
+	"GOHW-1/internal/model"
+	"context"
+)
+
+type RequestStatsRepo struct {
+	db db.Database
+}
+
+func NewRequestStats(database db.Database) *RequestStatsRepo {
+	return &RequestStatsRepo{db: database}
+}
+
+// Save stores a snapshot of request statistics, either all routes or none
+func (r *RequestStatsRepo) Save(ctx context.Context, stats []model.RouteStats) error {
+	return r.db.WithTx(ctx, func(ctx context.Context) error {
+		for _, routeStats := range stats {
+			_, err := r.db.Exec(ctx, `INSERT INTO request_stats(route, window_seconds, count, errors, error_rate, p50_ms, p95_ms, p99_ms, computed_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+				routeStats.Route, routeStats.WindowSeconds, routeStats.Count, routeStats.Errors, routeStats.ErrorRate,
+				routeStats.P50, routeStats.P95, routeStats.P99, routeStats.ComputedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// List retrieves the latest snapshots of a route (of all routes if route is empty), newest first
+func (r *RequestStatsRepo) List(ctx context.Context, route string, limit int) ([]model.RouteStats, error) {
+	var stats []model.RouteStats
+	if err := r.db.Select(ctx, &stats, `SELECT route, window_seconds, count, errors, error_rate, p50_ms, p95_ms, p99_ms, computed_at
+		FROM request_stats WHERE $1 = '' OR route = $1 ORDER BY computed_at DESC, route LIMIT $2`, route, limit); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// All retrieves all snapshots in the order they were saved
+func (r *RequestStatsRepo) All(ctx context.Context) ([]model.RouteStats, error) {
+	var stats []model.RouteStats
+	if err := r.db.Select(ctx, &stats, `SELECT route, window_seconds, count, errors, error_rate, p50_ms, p95_ms, p99_ms, computed_at
+		FROM request_stats ORDER BY id`); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// DeleteAll removes all snapshots
+func (r *RequestStatsRepo) DeleteAll(ctx context.Context) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM request_stats")
+	return err
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"GOHW-1/internal/metrics"
 	"GOHW-1/internal/model"
 )
 
@@ -25,17 +26,29 @@ func New(storage storage) Service {
 
 // CourierTakeOrder accepts and writes order from courier into file
 func (service Service) CourierTakeOrder(order model.Order) error {
-	return service.storage.CourierTakeOrder(order)
+	if err := service.storage.CourierTakeOrder(order); err != nil {
+		return err
+	}
+	metrics.OrderOperation(metrics.OrdersTaken, 1)
+	return nil
 }
 
 // CourierGiveOrder deletes given order from file
 func (service Service) CourierGiveOrder(orderID int) error {
-	return service.storage.CourierGiveOrder(orderID)
+	if err := service.storage.CourierGiveOrder(orderID); err != nil {
+		return err
+	}
+	metrics.OrderOperation(metrics.OrdersReturned, 1)
+	return nil
 }
 
 // ClientGiveOrder changes given orders' boolean variables `isGiven` to true
 func (service Service) ClientGiveOrder(clientID int, ordersID []string) error {
-	return service.storage.ClientGiveOrder(clientID, ordersID)
+	if err := service.storage.ClientGiveOrder(clientID, ordersID); err != nil {
+		return err
+	}
+	metrics.OrderOperation(metrics.OrdersGiven, len(ordersID))
+	return nil
 }
 
 // ClientGetOrders gets all client orders
@@ -45,7 +58,11 @@ func (service Service) ClientGetOrders(clientID int, n int, onlyUserOrders bool)
 
 // ClientRefund accepts refund from customer
 func (service Service) ClientRefund(clientID int, orderID int) error {
-	return service.storage.ClientRefund(clientID, orderID)
+	if err := service.storage.ClientRefund(clientID, orderID); err != nil {
+		return err
+	}
+	metrics.OrderOperation(metrics.OrdersRefunded, 1)
+	return nil
 }
 
 // RefundList returns slice of refunded orders
@@ -1,6 +1,9 @@
 package storage
 
-import "time"
+import (
+	"GOHW-1/internal/model"
+	"time"
+)
 
 type OrderDTO struct {
 	ID             int
@@ -9,6 +12,46 @@ type OrderDTO struct {
 	Weight         float64
 	Price          float64
 	Packaging      string
+	PickUpPointID  int64 `json:",omitempty"`
 	IsGiven        bool
 	GivenTime      time.Time
 }
+
+// NewOrderDTO returns the record of a stored order, the state is kept by the file the record is written to
+func NewOrderDTO(order model.StoredOrder) OrderDTO {
+	dto := OrderDTO{
+		ID:             order.ID,
+		ClientID:       order.ClientID,
+		ExpirationDate: order.ExpirationDate,
+		Weight:         order.Weight,
+		Price:          order.Price,
+		Packaging:      order.Packaging,
+		PickUpPointID:  order.PickUpPointID,
+		IsGiven:        order.GivenTime != nil,
+	}
+	if order.GivenTime != nil {
+		dto.GivenTime = *order.GivenTime
+	}
+	return dto
+}
+
+// StoredOrder returns the order of the record in the given state
+func (dto OrderDTO) StoredOrder(state string) model.StoredOrder {
+	storedOrder := model.StoredOrder{
+		Order: model.Order{
+			ID:             dto.ID,
+			ClientID:       dto.ClientID,
+			ExpirationDate: dto.ExpirationDate,
+			Weight:         dto.Weight,
+			Price:          dto.Price,
+			Packaging:      dto.Packaging,
+			PickUpPointID:  dto.PickUpPointID,
+		},
+		State: state,
+	}
+	if state != model.OrderAvailable && !dto.GivenTime.IsZero() {
+		givenTime := dto.GivenTime
+		storedOrder.GivenTime = &givenTime
+	}
+	return storedOrder
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"GOHW-1/internal/clock"
 	"GOHW-1/internal/model"
 	"bufio"
 	"encoding/json"
@@ -8,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log/slog"
 	"os"
 	"slices"
 	"strconv"
@@ -24,9 +26,13 @@ type Storage struct {
 	refundedOrdersFile  *os.File
 	pickUpPointsFile    *os.File
 	rwmutex             sync.RWMutex
+	clock               clock.Clock
+	policies            func() model.Policies
+	logger              *slog.Logger
 }
 
-func New() (Storage, error) {
+// New opens the storage files. Business rules applied to orders are returned by policies and checked against clock
+func New(clock clock.Clock, policies func() model.Policies, logger *slog.Logger) (Storage, error) {
 	availableOrdersFile, err := os.OpenFile(availableOrdersFileName, os.O_CREATE, 0777)
 	if err != nil {
 		return Storage{}, err
@@ -43,10 +49,25 @@ func New() (Storage, error) {
 			availableOrdersFile: availableOrdersFile,
 			refundedOrdersFile:  refundedOrdersFile,
 			pickUpPointsFile:    pickUpPointsFile,
-			rwmutex:             sync.RWMutex{}},
+			rwmutex:             sync.RWMutex{},
+			clock:               clock,
+			policies:            policies,
+			logger:              logger},
 		nil
 }
 
+// CheckFiles checks that the storage files exist and can be opened for reading and writing, it does not create them
+func CheckFiles() error {
+	for _, fileName := range []string{availableOrdersFileName, refundedOrdersFileName, pickUpPointsFileName} {
+		file, err := os.OpenFile(fileName, os.O_RDWR, 0)
+		if err != nil {
+			return err
+		}
+		file.Close()
+	}
+	return nil
+}
+
 // CourierTakeOrder accepts and writes order from courier into file
 func (s *Storage) CourierTakeOrder(order model.Order) error {
 	availableOrders, err := s.GetOrders(availableOrdersFileName)
@@ -54,9 +75,14 @@ func (s *Storage) CourierTakeOrder(order model.Order) error {
 		return err
 	}
 
-	if time.Now().After(order.ExpirationDate) {
+	policy := s.policies().For(order.PickUpPointID)
+	now := s.clock.Now()
+	if now.After(order.ExpirationDate) {
 		return errors.New("order expiration date in the past")
 	}
+	if policy.MaxStorage > 0 && order.ExpirationDate.Sub(now) > policy.MaxStorage {
+		return fmt.Errorf("order cannot be stored for more than %s", policy.MaxStorage)
+	}
 	for _, orderVal := range availableOrders {
 		if orderVal.ID == order.ID {
 			return errors.New("order has been already accepted")
@@ -70,6 +96,7 @@ func (s *Storage) CourierTakeOrder(order model.Order) error {
 		Weight:         order.Weight,
 		Price:          order.Price,
 		Packaging:      order.Packaging,
+		PickUpPointID:  order.PickUpPointID,
 		IsGiven:        false,
 		GivenTime:      time.Time{},
 	}
@@ -78,23 +105,27 @@ func (s *Storage) CourierTakeOrder(order model.Order) error {
 	if err = writeOrders(availableOrders, availableOrdersFileName); err != nil {
 		return err
 	}
+	s.logger.Debug("order taken from courier", "order_id", order.ID, "client_id", order.ClientID)
 	return nil
 }
 
-// CourierGiveOrder deletes given order from file
+// CourierGiveOrder deletes given order from file once the return grace period after its expiration date is over
 func (s *Storage) CourierGiveOrder(orderID int) error {
 	availableOrders, err := s.GetOrders(availableOrdersFileName)
 	if err != nil {
 		return err
 	}
 
+	policies := s.policies()
 	for ind, order := range availableOrders {
 		if order.ID == orderID {
-			if !order.IsGiven && time.Now().After(order.ExpirationDate) {
+			returnTime := order.ExpirationDate.Add(policies.For(order.PickUpPointID).ReturnGrace)
+			if !order.IsGiven && s.clock.Now().After(returnTime) {
 				availableOrders = append(availableOrders[:ind], availableOrders[ind+1:]...)
+				s.logger.Debug("expired order returned to courier", "order_id", orderID)
 				return writeOrders(availableOrders, availableOrdersFileName)
 			}
-			return errors.New("the order was given or the expiration date is not over yet")
+			return errors.New("the order was given or the expiration date and the return grace period are not over yet")
 		}
 	}
 
@@ -106,6 +137,7 @@ func (s *Storage) CourierGiveOrder(orderID int) error {
 	for ind, order := range refundedOrders {
 		if order.ID == orderID {
 			refundedOrders = append(refundedOrders[:ind], refundedOrders[ind+1:]...)
+			s.logger.Debug("refunded order returned to courier", "order_id", orderID)
 			return writeOrders(refundedOrders, refundedOrdersFileName)
 		}
 	}
@@ -113,15 +145,33 @@ func (s *Storage) CourierGiveOrder(orderID int) error {
 	return errors.New("the order was not found")
 }
 
+// storageError is an error of reading, decoding or writing the files. It matches model.ErrStorage
+// and keeps the message of the error
+type storageError struct {
+	err error
+}
+
+func (e storageError) Error() string {
+	return e.err.Error()
+}
+
+func (e storageError) Unwrap() []error {
+	return []error{model.ErrStorage, e.err}
+}
+
+func failure(err error) error {
+	return storageError{err: err}
+}
+
 // Write orders into file
 func writeOrders(orders []OrderDTO, fileName string) error {
 	rawBytes, err := json.Marshal(orders)
 	if err != nil {
-		return err
+		return failure(err)
 	}
 
 	if err = os.WriteFile(fileName, rawBytes, 0777); err != nil {
-		return err
+		return failure(err)
 	}
 	return nil
 }
@@ -134,13 +184,17 @@ func (s *Storage) GetOrders(fileName string) ([]OrderDTO, error) {
 	} else if fileName == refundedOrdersFileName {
 		file = s.refundedOrdersFile
 	} else {
-		return nil, errors.New("file not found")
+		return nil, failure(errors.New("file not found"))
 	}
 
+	// the file is read from the beginning every time, it is rewritten by name after changes
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, failure(err)
+	}
 	reader := bufio.NewReader(file)
 	rawBytes, err := io.ReadAll(reader)
 	if err != nil {
-		return nil, err
+		return nil, failure(err)
 	}
 
 	var orders []OrderDTO
@@ -149,11 +203,34 @@ func (s *Storage) GetOrders(fileName string) ([]OrderDTO, error) {
 	}
 
 	if err = json.Unmarshal(rawBytes, &orders); err != nil {
-		return nil, err
+		return nil, failure(err)
 	}
 	return orders, nil
 }
 
+// AvailableOrders returns orders that are kept or were given to clients
+func (s *Storage) AvailableOrders() ([]OrderDTO, error) {
+	return s.GetOrders(availableOrdersFileName)
+}
+
+// RefundedOrders returns orders refunded by clients
+func (s *Storage) RefundedOrders() ([]OrderDTO, error) {
+	return s.GetOrders(refundedOrdersFileName)
+}
+
+// Restore replaces all orders and pick-up points of the files
+func (s *Storage) Restore(availableOrders []OrderDTO, refundedOrders []OrderDTO, pickUpPoints []model.PickUpPoint) error {
+	s.logger.Debug("restoring the storage", "available_orders", len(availableOrders),
+		"refunded_orders", len(refundedOrders), "pick_up_points", len(pickUpPoints))
+	if err := writeOrders(availableOrders, availableOrdersFileName); err != nil {
+		return err
+	}
+	if err := writeOrders(refundedOrders, refundedOrdersFileName); err != nil {
+		return err
+	}
+	return s.writePickUpPoints(pickUpPoints)
+}
+
 // ClientGiveOrder changes given orders' boolean variables `isGiven` to true
 func (s *Storage) ClientGiveOrder(clientID int, ordersID []string) error {
 	availableOrders, err := s.GetOrders(availableOrdersFileName)
@@ -170,13 +247,14 @@ func (s *Storage) ClientGiveOrder(clientID int, ordersID []string) error {
 		isOrderPresent[atoi] = true
 	}
 
+	policies := s.policies()
 	count := 0
 	for _, order := range availableOrders {
 		if isOrderPresent[order.ID] {
 			if order.IsGiven {
 				return errors.New("order has already been given")
 			}
-			if time.Now().After(order.ExpirationDate) {
+			if s.clock.Now().After(order.ExpirationDate.Add(policies.For(order.PickUpPointID).PickUpGrace)) {
 				return errors.New("order expired")
 			}
 			if order.ClientID != clientID {
@@ -193,7 +271,7 @@ func (s *Storage) ClientGiveOrder(clientID int, ordersID []string) error {
 	for ind, order := range availableOrders {
 		if isOrderPresent[order.ID] {
 			order.IsGiven = true
-			order.GivenTime = time.Now()
+			order.GivenTime = s.clock.Now()
 			availableOrders = append(append(availableOrders[:ind], order), availableOrders[ind+1:]...)
 		}
 	}
@@ -201,6 +279,7 @@ func (s *Storage) ClientGiveOrder(clientID int, ordersID []string) error {
 	if err = writeOrders(availableOrders, availableOrdersFileName); err != nil {
 		return err
 	}
+	s.logger.Debug("orders given to client", "client_id", clientID, "orders", len(ordersID))
 	return nil
 }
 
@@ -222,6 +301,7 @@ func (s *Storage) ClientGetOrders(clientID int, n int, onlyUserOrders bool) ([]m
 				Weight:         order.Weight,
 				Price:          order.Price,
 				Packaging:      order.Packaging,
+				PickUpPointID:  order.PickUpPointID,
 			})
 		}
 		if n != -1 && len(clientOrders) >= n {
@@ -231,7 +311,7 @@ func (s *Storage) ClientGetOrders(clientID int, n int, onlyUserOrders bool) ([]m
 	return clientOrders, nil
 }
 
-// ClientRefund accepts refund from customer
+// ClientRefund accepts refund from customer if the order was given within the refund window of its pick-up point
 func (s *Storage) ClientRefund(clientID int, orderID int) error {
 	availableOrders, err := s.GetOrders(availableOrdersFileName)
 	if err != nil {
@@ -246,7 +326,8 @@ func (s *Storage) ClientRefund(clientID int, orderID int) error {
 	for ind, order := range availableOrders {
 		if order.ID == orderID {
 			if order.ClientID == clientID {
-				if order.IsGiven && time.Since(order.GivenTime) < 48*time.Hour {
+				refundWindow := s.policies().For(order.PickUpPointID).RefundWindow
+				if order.IsGiven && s.clock.Now().Sub(order.GivenTime) < refundWindow {
 					availableOrders = append(availableOrders[:ind], availableOrders[ind+1:]...)
 					err = writeOrders(availableOrders, availableOrdersFileName)
 					if err != nil {
@@ -258,9 +339,10 @@ func (s *Storage) ClientRefund(clientID int, orderID int) error {
 					if err != nil {
 						return err
 					}
+					s.logger.Debug("order refunded by client", "order_id", orderID, "client_id", clientID)
 					return nil
 				}
-				return errors.New("it has been more than 2 days since it was given or order was not given")
+				return fmt.Errorf("it has been more than %s since it was given or order was not given", refundWindow)
 			}
 			return errors.New("order does not belong to the client")
 		}
@@ -268,19 +350,20 @@ func (s *Storage) ClientRefund(clientID int, orderID int) error {
 	return errors.New("order was not found")
 }
 
-// RefundList returns slice of refunded orders
+// RefundList returns a page of refunded orders, the page size is taken from the default policy
 func (s *Storage) RefundList(pageNumber int) ([]model.Order, error) {
 	refundedOrders, err := s.GetOrders(refundedOrdersFileName)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(refundedOrders) < (pageNumber-1)*10+1 {
+	pageSize := s.policies().Default.PageSize
+	if len(refundedOrders) < (pageNumber-1)*pageSize+1 {
 		return nil, errors.New("page does not exists")
 	}
 
 	ordersOnPage := make([]model.Order, 0)
-	for _, order := range refundedOrders[(pageNumber-1)*10 : min(pageNumber*10, len(refundedOrders))] {
+	for _, order := range refundedOrders[(pageNumber-1)*pageSize : min(pageNumber*pageSize, len(refundedOrders))] {
 		ordersOnPage = append(ordersOnPage, model.Order{
 			ID:             order.ID,
 			ClientID:       order.ClientID,
@@ -288,6 +371,7 @@ func (s *Storage) RefundList(pageNumber int) ([]model.Order, error) {
 			Weight:         order.Weight,
 			Price:          order.Price,
 			Packaging:      order.Packaging,
+			PickUpPointID:  order.PickUpPointID,
 		})
 	}
 	return ordersOnPage, nil
@@ -305,6 +389,7 @@ func (s *Storage) PickUpPointWrite(pickUpPoint model.PickUpPoint) error {
 	if err = s.writePickUpPoints(pickUpPoints); err != nil {
 		return err
 	}
+	s.logger.Debug("pick-up point written", "id", pickUpPoint.ID)
 	return nil
 }
 
@@ -312,13 +397,16 @@ func (s *Storage) PickUpPointWrite(pickUpPoint model.PickUpPoint) error {
 func (s *Storage) writePickUpPoints(pickUpPoints []model.PickUpPoint) error {
 	rawBytes, err := json.Marshal(pickUpPoints)
 	if err != nil {
-		return err
+		return failure(err)
 	}
 
 	s.rwmutex.Lock()
 	err = os.WriteFile(pickUpPointsFileName, rawBytes, 0777)
 	s.rwmutex.Unlock()
-	return err
+	if err != nil {
+		return failure(err)
+	}
+	return nil
 }
 
 // PickUpPointsRead gets a slice with all pick-up points
@@ -328,13 +416,13 @@ func (s *Storage) PickUpPointsRead() ([]model.PickUpPoint, error) {
 
 	file, err := os.Open(pickUpPointsFileName)
 	if err != nil {
-		return nil, fmt.Errorf("unable to open the file")
+		return nil, failure(fmt.Errorf("unable to open the file"))
 	}
 	defer file.Close()
 
 	fileInfo, err := file.Stat()
 	if err != nil {
-		return nil, fmt.Errorf("unable to get the information about the file")
+		return nil, failure(fmt.Errorf("unable to get the information about the file"))
 	}
 
 	// Is file empty
@@ -344,12 +432,12 @@ func (s *Storage) PickUpPointsRead() ([]model.PickUpPoint, error) {
 
 	rawBytes, err := ioutil.ReadAll(file)
 	if err != nil {
-		return nil, err
+		return nil, failure(err)
 	}
 
 	var pickUpPoints []model.PickUpPoint
 	if err := json.Unmarshal(rawBytes, &pickUpPoints); err != nil {
-		return nil, err
+		return nil, failure(err)
 	}
 
 	return pickUpPoints, nil
@@ -1,10 +1,13 @@
 package storage
 
 import (
+	"GOHW-1/internal/clock"
 	"GOHW-1/internal/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"os"
 	"testing"
+	"time"
 )
 
 func TestStorage_PickUpPointsRead(t *testing.T) {
@@ -40,6 +43,7 @@ func TestStorage_PickUpPointsRead(t *testing.T) {
 
 		// assert
 		require.Equal(t, "unable to open the file", err.Error())
+		assert.ErrorIs(t, err, model.ErrStorage)
 		assert.Equal(t, sampleData, pickUpPoints)
 	})
 }
@@ -82,3 +86,117 @@ func TestStorage_WritePickUpPoints(t *testing.T) {
 		assert.Equal(t, []model.PickUpPoint{pickUpPoint, pickUpPoint2}, pickUpPointsResult)
 	})
 }
+
+func TestStorage_OrderTimeRules(t *testing.T) {
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	policies := model.Policies{
+		Default: model.Policy{RefundWindow: 48 * time.Hour, PageSize: 10, MaxStorage: 72 * time.Hour},
+		PickUpPoints: map[int64]model.Policy{
+			7: {RefundWindow: 24 * time.Hour, PageSize: 10, PickUpGrace: time.Hour, ReturnGrace: 2 * time.Hour},
+		},
+	}
+	order := model.Order{ID: 1, ClientID: 2, ExpirationDate: now.Add(24 * time.Hour), Weight: 1, Price: 100, Packaging: model.Film}
+
+	t.Run("expiration in the past test", func(t *testing.T) {
+		// arrange
+		storage := setUpOrders(t, clock.NewFake(now), policies)
+		expired := order
+		expired.ExpirationDate = now.Add(-time.Nanosecond)
+
+		// act
+		err := storage.CourierTakeOrder(expired)
+
+		// assert
+		require.Error(t, err)
+		assert.Equal(t, "order expiration date in the past", err.Error())
+	})
+	t.Run("max storage test", func(t *testing.T) {
+		// arrange
+		storage := setUpOrders(t, clock.NewFake(now), policies)
+		longStored := order
+		longStored.ExpirationDate = now.Add(72*time.Hour + time.Nanosecond)
+
+		// act
+		err := storage.CourierTakeOrder(longStored)
+
+		// assert
+		require.Error(t, err)
+		assert.Equal(t, "order cannot be stored for more than 72h0m0s", err.Error())
+	})
+	t.Run("refund window test", func(t *testing.T) {
+		// arrange
+		fake := clock.NewFake(now)
+		storage := setUpOrders(t, fake, policies)
+		require.NoError(t, storage.CourierTakeOrder(order))
+		require.NoError(t, storage.ClientGiveOrder(2, []string{"1"}))
+		fake.Advance(48 * time.Hour)
+
+		// act
+		lateErr := storage.ClientRefund(2, 1)
+		fake.Advance(-time.Nanosecond)
+		err := storage.ClientRefund(2, 1)
+
+		// assert
+		require.Error(t, lateErr)
+		assert.Equal(t, "it has been more than 48h0m0s since it was given or order was not given", lateErr.Error())
+		require.NoError(t, err)
+		refunded, err := storage.RefundList(1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, len(refunded))
+	})
+	t.Run("pick-up point grace periods test", func(t *testing.T) {
+		// arrange
+		fake := clock.NewFake(now)
+		storage := setUpOrders(t, fake, policies)
+		given, kept := order, order
+		given.PickUpPointID, kept.PickUpPointID, kept.ID = 7, 7, 3
+		require.NoError(t, storage.CourierTakeOrder(given))
+		require.NoError(t, storage.CourierTakeOrder(kept))
+		fake.Set(order.ExpirationDate.Add(time.Hour))
+
+		// act
+		earlyReturnErr := storage.CourierGiveOrder(3)
+		giveErr := storage.ClientGiveOrder(2, []string{"1"})
+		fake.Advance(time.Nanosecond)
+		expiredErr := storage.ClientGiveOrder(2, []string{"3"})
+		fake.Advance(time.Hour)
+		returnErr := storage.CourierGiveOrder(3)
+		fake.Set(order.ExpirationDate.Add(25 * time.Hour))
+		lateRefundErr := storage.ClientRefund(2, 1)
+
+		// assert
+		require.Error(t, earlyReturnErr)
+		assert.Equal(t, "the order was given or the expiration date and the return grace period are not over yet", earlyReturnErr.Error())
+		assert.NoError(t, giveErr)
+		require.Error(t, expiredErr)
+		assert.Equal(t, "order expired", expiredErr.Error())
+		assert.NoError(t, returnErr)
+		require.Error(t, lateRefundErr)
+		assert.Equal(t, "it has been more than 24h0m0s since it was given or order was not given", lateRefundErr.Error())
+	})
+}
+
+func TestCheckFiles(t *testing.T) {
+	t.Run("smoke test", func(t *testing.T) {
+		// arrange
+		setUpOrders(t, clock.NewFake(time.Now()), model.Policies{})
+
+		// act
+		err := CheckFiles()
+
+		// assert
+		assert.NoError(t, err)
+	})
+	t.Run("missing file test", func(t *testing.T) {
+		// arrange
+		setUpOrders(t, clock.NewFake(time.Now()), model.Policies{})
+		require.NoError(t, os.Remove(refundedOrdersFileName))
+
+		// act
+		err := CheckFiles()
+
+		// assert
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.NoFileExists(t, refundedOrdersFileName)
+	})
+}
@@ -1,11 +1,15 @@
 package storage
 
 import (
+	"GOHW-1/internal/clock"
+	"GOHW-1/internal/logger"
 	"GOHW-1/internal/model"
 	"encoding/json"
 	"fmt"
+	"github.com/stretchr/testify/require"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -65,3 +69,21 @@ func readFile(pickUpPointsFileName string) ([]model.PickUpPoint, error) {
 func tearDown(filename string) {
 	os.Remove(filename)
 }
+
+// setUpOrders returns the storage of orders in empty temporary files, tests using it must not run in parallel
+func setUpOrders(t *testing.T, clock clock.Clock, policies model.Policies) *Storage {
+	t.Helper()
+	dir := t.TempDir()
+	availableOrdersFileName = filepath.Join(dir, "available_orders.json")
+	refundedOrdersFileName = filepath.Join(dir, "refunded_orders.json")
+	pickUpPointsFileName = filepath.Join(dir, "pick_up_points.json")
+
+	storage, err := New(clock, func() model.Policies { return policies }, logger.Discard())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		storage.availableOrdersFile.Close()
+		storage.refundedOrdersFile.Close()
+		storage.pickUpPointsFile.Close()
+	})
+	return &storage
+}
@@ -0,0 +1,42 @@
+package tracing
+
+import (
+	"GOHW-1/internal/configuration"
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSetup(t *testing.T) {
+	t.Run("stdout test", func(t *testing.T) {
+		// arrange
+		var out bytes.Buffer
+		tracingConfig := configuration.NewTracingConfig()
+		tracingConfig.Exporter = configuration.TracingStdout
+		tracingConfig.ServiceName = "gohw-test"
+		shutdown, err := Setup(context.Background(), tracingConfig, &out)
+		require.NoError(t, err)
+
+		// act
+		_, span := Tracer().Start(context.Background(), "GET /pick-up-point")
+		span.End()
+		require.NoError(t, shutdown(context.Background()))
+
+		// assert
+		assert.Contains(t, out.String(), `"Name":"GET /pick-up-point"`)
+		assert.Contains(t, out.String(), "gohw-test")
+	})
+	t.Run("unknown exporter test", func(t *testing.T) {
+		// arrange
+		tracingConfig := configuration.NewTracingConfig()
+		tracingConfig.Exporter = "zipkin"
+
+		// act
+		_, err := Setup(context.Background(), tracingConfig, &bytes.Buffer{})
+
+		// assert
+		assert.EqualError(t, err, `unknown tracing exporter "zipkin"`)
+	})
+}
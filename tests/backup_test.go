@@ -0,0 +1,52 @@
+//go:build integration
+
+package tests
+
+import (
+	"GOHW-1/internal/backup"
+	"GOHW-1/internal/model"
+	"GOHW-1/internal/repository/postgresql"
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPostgresBackend(t *testing.T) {
+	backend := backup.NewPostgresBackend(tdb.DB, postgresql.NewOrders(tdb.DB), postgresql.NewPickUpPoints(tdb.DB),
+		postgresql.NewRequestStats(tdb.DB))
+	ctx := context.Background()
+
+	t.Run("restore export test", func(t *testing.T) {
+		// arrange
+		givenTime := time.Date(2024, 4, 2, 12, 0, 0, 0, time.UTC)
+		snapshot := &backup.Snapshot{
+			PickUpPoints: []model.PickUpPoint{{ID: 7, Name: "Central", Address: "Main street, 1", Contact: "+7 999 000-00-01"}},
+			Orders: []model.StoredOrder{{Order: model.Order{ID: 1, ClientID: 10, ExpirationDate: givenTime.Add(time.Hour)},
+				State: model.OrderGiven, GivenTime: &givenTime}},
+			Refunds: []model.StoredOrder{{Order: model.Order{ID: 2, ClientID: 10, ExpirationDate: givenTime.Add(time.Hour)},
+				State: model.OrderRefunded, GivenTime: &givenTime}},
+			RequestStats: []model.RouteStats{{Route: "GET /pick-up-point", WindowSeconds: 60, Count: 1, ComputedAt: givenTime}},
+		}
+		var archive bytes.Buffer
+		_, err := backup.Write(&archive, "postgres", givenTime, snapshot)
+		require.NoError(t, err)
+
+		// act
+		_, err = backup.Restore(ctx, backend, &archive)
+		require.NoError(t, err)
+		exported, err := backend.Read(ctx)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, snapshot.PickUpPoints, exported.PickUpPoints)
+		require.Len(t, exported.Orders, 1)
+		assert.Equal(t, model.OrderGiven, exported.Orders[0].State)
+		require.Len(t, exported.Refunds, 1)
+		assert.Equal(t, 2, exported.Refunds[0].ID)
+		require.Len(t, exported.RequestStats, 1)
+		assert.Equal(t, snapshot.RequestStats[0].Route, exported.RequestStats[0].Route)
+	})
+}
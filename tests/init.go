@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"github.com/IBM/sarama"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"testing"
@@ -41,28 +42,30 @@ func init() {
 	tdb = newFromEnv(dbCredentials)
 
 	ctx := context.Background()
-	database, err := db.NewDb(ctx, dbCredentials)
+	database, err := db.NewDb(ctx, dbCredentials, slog.Default())
 	if err != nil {
 		log.Fatalf("cannot connect to database: %v", err)
 	}
 	defer database.GetPool(ctx).Close()
 
 	// Kafka producer initialization
-	topicName := configuration.GetTopicName()
-	brokers := configuration.GetBrokers()
-	kafkaProducer, err := kafka.NewProducer(*brokers)
+	kafkaConfig, err := configuration.LoadKafkaConfig()
+	if err != nil {
+		log.Fatalf("invalid kafka configuration: %v", err)
+	}
+	kafkaProducer, err := kafka.NewProducer(kafkaConfig, slog.Default())
 	if err != nil {
 		log.Fatalf("cannot connect to kafka: %v", err)
 	}
 	//defer kafkaProducer.Close()
 
-	sender := controller.NewKafkaSender(kafkaProducer, *topicName)
+	sender := controller.NewKafkaSender(kafkaProducer, kafkaConfig.Topic)
 	pickUpPointController = controller.NewPickUpPointController(database, sender)
 
 }
 
 func newFromEnv(dbCredentials *configuration.DBCredentials) *TDB {
-	newDb, err := db.NewDb(context.Background(), dbCredentials)
+	newDb, err := db.NewDb(context.Background(), dbCredentials, slog.Default())
 	if err != nil {
 		panic(err)
 	}
@@ -70,14 +73,20 @@ func newFromEnv(dbCredentials *configuration.DBCredentials) *TDB {
 }
 
 func getKafkaMessage(t *testing.T) LoggingMessage {
-	brokers := configuration.GetBrokers()
-	consumer, err := sarama.NewConsumer(*brokers, nil)
+	kafkaConfig, err := configuration.LoadKafkaConfig()
+	if err != nil {
+		panic("Invalid kafka configuration: " + err.Error())
+	}
+	config, err := kafka.NewConfig(kafkaConfig)
+	if err != nil {
+		panic("Invalid kafka configuration: " + err.Error())
+	}
+	consumer, err := sarama.NewConsumer(kafkaConfig.Brokers, config)
 	if err != nil {
 		panic("Failed to start Sarama consumer: " + err.Error())
 	}
 
-	topicName := configuration.GetTopicName()
-	partitionConsumer, err := consumer.ConsumePartition(*topicName, 0, sarama.OffsetNewest)
+	partitionConsumer, err := consumer.ConsumePartition(kafkaConfig.Topic, 0, sarama.OffsetNewest)
 	if err != nil {
 		panic("Failed to start partition consumer: " + err.Error())
 	}
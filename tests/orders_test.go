@@ -0,0 +1,72 @@
+//go:build integration
+
+package tests
+
+import (
+	"GOHW-1/internal/model"
+	"GOHW-1/internal/repository/postgresql"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestOrderRepo_Upsert(t *testing.T) {
+	repo := postgresql.NewOrders(tdb.DB)
+	ctx := context.Background()
+
+	t.Run("insert and update test", func(t *testing.T) {
+		// arrange
+		givenTime := time.Date(2024, 4, 2, 12, 0, 0, 0, time.UTC)
+		order := model.StoredOrder{
+			Order: model.Order{ID: 900001, ClientID: 1, ExpirationDate: time.Date(2024, 4, 10, 12, 0, 0, 0, time.UTC),
+				Weight: 1.5, Price: 100, Packaging: "box"},
+			State: model.OrderAvailable,
+		}
+		require.NoError(t, repo.Upsert(ctx, order))
+		order.State, order.GivenTime = model.OrderGiven, &givenTime
+
+		// act
+		err := repo.Upsert(ctx, order)
+
+		// assert
+		require.NoError(t, err)
+		orders, err := repo.List(ctx)
+		require.NoError(t, err)
+		var found []model.StoredOrder
+		for _, stored := range orders {
+			if stored.ID == order.ID {
+				found = append(found, stored)
+			}
+		}
+		require.Len(t, found, 1)
+		assert.Equal(t, model.OrderGiven, found[0].State)
+		require.NotNil(t, found[0].GivenTime)
+		assert.True(t, givenTime.Equal(*found[0].GivenTime))
+	})
+}
+
+func TestPickUpPointRepo_Upsert(t *testing.T) {
+	repo := postgresql.NewPickUpPoints(tdb.DB)
+	ctx := context.Background()
+
+	t.Run("keeps ID test", func(t *testing.T) {
+		// arrange
+		pickUpPoint := model.PickUpPoint{ID: 900001, Name: "Imported", Address: "Main street, 1", Contact: "+7 999 000-00-01"}
+
+		// act
+		err := repo.Upsert(ctx, pickUpPoint)
+		require.NoError(t, err)
+		err = repo.SyncIDSequence(ctx)
+
+		// assert
+		require.NoError(t, err)
+		stored, err := repo.GetByID(ctx, pickUpPoint.ID)
+		require.NoError(t, err)
+		assert.Equal(t, pickUpPoint, *stored)
+		id, err := repo.Create(ctx, &model.PickUpPoint{Name: "Created", Address: "Main street, 2", Contact: "+7 999 000-00-02"})
+		require.NoError(t, err)
+		assert.Greater(t, id, pickUpPoint.ID)
+	})
+}
@@ -0,0 +1,102 @@
+//go:build integration
+
+package tests
+
+import (
+	"GOHW-1/internal/db"
+	"GOHW-1/internal/model"
+	"GOHW-1/internal/repository/postgresql"
+	"GOHW-1/tests/fixtures"
+	"context"
+	"errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDatabase_WithTx(t *testing.T) {
+	repo := postgresql.NewPickUpPoints(tdb.DB)
+	ctx := context.Background()
+
+	t.Run("commit test", func(t *testing.T) {
+		// arrange
+		var id int64
+
+		// act
+		err := tdb.DB.WithTx(ctx, func(ctx context.Context) error {
+			var err error
+			id, err = repo.Create(ctx, fixtures.PickUpPoint().Valid().P())
+			return err
+		})
+
+		// assert
+		require.NoError(t, err)
+		_, err = repo.GetByID(ctx, id)
+		assert.NoError(t, err)
+	})
+	t.Run("rollback test", func(t *testing.T) {
+		// arrange
+		var id int64
+		failure := errors.New("failure")
+
+		// act
+		err := tdb.DB.WithTx(ctx, func(ctx context.Context) error {
+			var err error
+			if id, err = repo.Create(ctx, fixtures.PickUpPoint().Valid().P()); err != nil {
+				return err
+			}
+			return failure
+		})
+
+		// assert
+		assert.ErrorIs(t, err, failure)
+		_, err = repo.GetByID(ctx, id)
+		assert.ErrorIs(t, err, model.ErrObjectNotFound)
+	})
+	t.Run("savepoint test", func(t *testing.T) {
+		// arrange
+		var outerID, innerID int64
+
+		// act
+		err := tdb.DB.WithTx(ctx, func(ctx context.Context) error {
+			var err error
+			if outerID, err = repo.Create(ctx, fixtures.PickUpPoint().Valid().P()); err != nil {
+				return err
+			}
+			innerErr := tdb.DB.WithTx(ctx, func(ctx context.Context) error {
+				if innerID, err = repo.Create(ctx, fixtures.PickUpPoint().Valid().P()); err != nil {
+					return err
+				}
+				return errors.New("inner failure")
+			})
+			assert.Error(t, innerErr)
+			return nil
+		})
+
+		// assert
+		require.NoError(t, err)
+		_, err = repo.GetByID(ctx, outerID)
+		assert.NoError(t, err)
+		_, err = repo.GetByID(ctx, innerID)
+		assert.ErrorIs(t, err, model.ErrObjectNotFound)
+	})
+	t.Run("serialization failure retry test", func(t *testing.T) {
+		// arrange
+		attempts := 0
+
+		// act
+		err := tdb.DB.WithTx(ctx, func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+			}
+			return nil
+		}, db.WithIsolation(pgx.Serializable), db.WithRetries(2))
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+}